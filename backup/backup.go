@@ -0,0 +1,188 @@
+// Package backup is the public Go API of s3-backup, for programs that back up directories to
+// S3 without running the CLI. It is a thin layer over the internal packages, so backups made
+// through it behave exactly like those of the s3-backup command.
+//
+// Backups do not return per-file results or statistics. Files that could not be backed up are
+// reported as BackupError values joined into the returned error, and the files and bytes of
+// completed backups are summarized by Service.ListBackupSessions.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"s3-backup/internal/config"
+	"s3-backup/internal/s3"
+	"time"
+
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Config holds the backup configuration. It has the same fields, YAML keys, and defaults as
+// the configuration of the s3-backup command, see LoadConfig.
+type Config = config.Config
+
+// API is the subset of the S3 client used by a Service, see WithClient.
+type API = s3.API
+
+// PresignAPI is the subset of the S3 pre-sign client used to share uploaded objects, see WithPresignClient.
+type PresignAPI = s3.PresignAPI
+
+// EstimateResult summarizes the data a backup would transfer, see Service.EstimateBackup.
+type EstimateResult = s3.EstimateResult
+
+// PlannedObject is an object a backup would upload, see Service.PlanBackup.
+type PlannedObject = s3.PlannedObject
+
+// BackupSession summarizes the objects uploaded by one backup, see Service.ListBackupSessions.
+type BackupSession = s3.BackupSession
+
+// BackupError is joined into the error of a backup for every file that could not be backed up.
+// Use errors.As to recover the path of the failed files.
+type BackupError = s3.BackupError
+
+// ErrDirectoryNotConfigured is returned by Service.BackupDir for a directory that is not one of
+// the configured backup directories.
+var ErrDirectoryNotConfigured = s3.ErrDirectoryNotConfigured
+
+// Option configures optional dependencies of a Service created by NewService.
+type Option = s3.Option
+
+// WithClient makes the service send its requests to client instead of an S3 client created
+// from the config, e.g. a client shared with the rest of the program.
+func WithClient(client API) Option {
+	return s3.WithClient(client)
+}
+
+// WithClientOptions applies opts to the S3 client created from the config.
+func WithClientOptions(opts ...func(*awss3.Options)) Option {
+	return s3.WithClientOptions(opts...)
+}
+
+// WithPresignClient makes the service pre-sign uploaded objects with client when pre-signing
+// is enabled in the config.
+func WithPresignClient(client PresignAPI) Option {
+	return s3.WithPresignClient(client)
+}
+
+// WithLogger makes the service log to l instead of the default logger.
+func WithLogger(l *slog.Logger) Option {
+	return s3.WithLogger(l)
+}
+
+// LoadConfig loads the configuration the way the s3-backup command does, from the YAML file
+// named by S3_BACKUP_CONFIG_FILE and the BACKUP_* environment variables.
+func LoadConfig() (*Config, error) {
+	return config.NewConfig()
+}
+
+// Service backs up the configured directories to S3, once or on a schedule.
+// A Service must be closed with Close when it is no longer needed.
+type Service struct {
+	svc *s3.Service
+}
+
+// NewService validates cfg and creates a Service from it. A Config loaded with LoadConfig
+// is already valid; one built by hand is checked with the same rules.
+func NewService(ctx context.Context, cfg *Config, opts ...Option) (*Service, error) {
+	const op = "backup.NewService"
+
+	if cfg == nil {
+		return nil, fmt.Errorf("%s: %w", op, s3.ErrNilConfig)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	svc, err := s3.NewS3Service(ctx, cfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Service{svc: svc}, nil
+}
+
+// Backup uploads the configured directories under a new timestamp prefix. Files that fail
+// are reported as BackupError values joined into the returned error.
+func (s *Service) Backup(ctx context.Context) error {
+	return s.svc.Backup(ctx)
+}
+
+// BackupDir backs up a single configured backup directory the way Backup does for all of them.
+// It returns ErrDirectoryNotConfigured if dir is not one of the configured directories.
+func (s *Service) BackupDir(ctx context.Context, dir string) error {
+	return s.svc.BackupDir(ctx, dir)
+}
+
+// ReloadConfig applies the backup directories and recursive mode of cfg to the service.
+// Other settings only take effect in a new Service. On error the current settings are kept.
+func (s *Service) ReloadConfig(cfg *Config) error {
+	return s.svc.ReloadConfig(cfg)
+}
+
+// Config returns a copy of the effective configuration of the service, including the backup
+// directories and recursive mode of the last ReloadConfig. Changing it has no effect on the
+// service.
+func (s *Service) Config() *Config {
+	return s.svc.Config()
+}
+
+// CheckBucketVersioning verifies that versioning is enabled on the bucket if the versioning
+// check is enabled in the config, the way the s3-backup command does on startup.
+func (s *Service) CheckBucketVersioning(ctx context.Context) error {
+	return s.svc.CheckBucketVersioning(ctx)
+}
+
+// ConfigureBucket applies the bucket settings enabled in the config, such as the
+// Intelligent-Tiering archive tiers, the way the s3-backup command does on startup.
+func (s *Service) ConfigureBucket(ctx context.Context) error {
+	return s.svc.ConfigureBucket(ctx)
+}
+
+// TagBucket tags the bucket as managed by s3-backup if bucket tagging is enabled in the config,
+// keeping its other tags.
+func (s *Service) TagBucket(ctx context.Context) error {
+	return s.svc.TagBucket(ctx)
+}
+
+// Start runs backups on the configured cron schedule until ctx is cancelled or Stop is called.
+func (s *Service) Start(ctx context.Context) error {
+	return s.svc.Start(ctx)
+}
+
+// Stop signals a running scheduler to stop. It reports whether this call stopped it.
+func (s *Service) Stop() bool {
+	return s.svc.Stop()
+}
+
+// Close stops the scheduler, waits for a running scheduled backup, and saves the incremental
+// backup cache. It is safe to call more than once.
+func (s *Service) Close() error {
+	return s.svc.Close()
+}
+
+// EstimateBackup computes the number of files, total size, and PUT cost of a backup without
+// uploading anything.
+func (s *Service) EstimateBackup(ctx context.Context) (EstimateResult, error) {
+	return s.svc.EstimateBackup(ctx)
+}
+
+// PlanBackup returns the objects a backup started now would upload, without uploading anything.
+func (s *Service) PlanBackup(ctx context.Context) ([]PlannedObject, error) {
+	return s.svc.PlanBackup(ctx)
+}
+
+// ListBackupSessions returns the backups in the bucket that started at or after since, oldest first.
+func (s *Service) ListBackupSessions(ctx context.Context, since time.Time) ([]BackupSession, error) {
+	return s.svc.ListBackupSessions(ctx, since)
+}
+
+// PruneOldBackups deletes the backups older than the configured retention period.
+func (s *Service) PruneOldBackups(ctx context.Context) error {
+	return s.svc.PruneOldBackups(ctx)
+}
+
+// UpcomingRuns returns the next n times the configured cron schedule triggers a backup.
+func (s *Service) UpcomingRuns(n int) ([]time.Time, error) {
+	return s.svc.UpcomingRuns(n)
+}
@@ -0,0 +1,43 @@
+package backup
+
+import (
+	"context"
+	"s3-backup/internal/config"
+	"s3-backup/internal/s3"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		cfg     *Config
+		wantErr error
+	}{
+		"nil config": {
+			wantErr: s3.ErrNilConfig,
+		},
+		"missing backup directories": {
+			cfg:     &Config{AWSRegion: "us-east-1", S3Bucket: "test-bucket"},
+			wantErr: config.ErrNoBackupDirs,
+		},
+		"invalid region": {
+			cfg:     &Config{BackupDirs: []string{t.TempDir()}, AWSRegion: "nowhere", S3Bucket: "test-bucket"},
+			wantErr: config.ErrInvalidAWSRegion,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := NewService(context.Background(), tc.cfg)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Nil(t, svc)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package backup_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"s3-backup/backup"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func ExampleNewService() {
+	ctx := context.Background()
+
+	dir := exampleDir()
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	cfg := &backup.Config{
+		BackupDirs: []string{filepath.Join(dir, "documents")},
+		Recursive:  true,
+		AWSRegion:  "us-east-1",
+		S3Bucket:   "example-bucket",
+	}
+
+	// Without WithClient, the service creates an S3 client from cfg and the AWS environment
+	svc, err := backup.NewService(ctx, cfg, backup.WithClient(&memoryClient{}), backup.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	planned, err := svc.PlanBackup(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, obj := range planned {
+		fmt.Printf("%s (%d bytes)\n", withoutTimestamp(obj.S3Key), obj.SizeBytes)
+	}
+	// Output:
+	// documents/invoices/invoice-001.txt (7 bytes)
+	// documents/report.txt (6 bytes)
+}
+
+func ExampleService_Backup() {
+	ctx := context.Background()
+
+	dir := exampleDir()
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	cfg := &backup.Config{
+		BackupDirs: []string{filepath.Join(dir, "documents")},
+		Recursive:  true,
+		AWSRegion:  "us-east-1",
+		S3Bucket:   "example-bucket",
+	}
+
+	client := &memoryClient{}
+	svc, err := backup.NewService(ctx, cfg, backup.WithClient(client), backup.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	if err := svc.Backup(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, key := range client.keys() {
+		fmt.Println(withoutTimestamp(key))
+	}
+	// Output:
+	// documents/invoices/invoice-001.txt
+	// documents/report.txt
+}
+
+func ExampleService_BackupDir() {
+	ctx := context.Background()
+
+	dir := exampleDir()
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	documents := filepath.Join(dir, "documents")
+	cfg := &backup.Config{
+		BackupDirs: []string{documents, filepath.Join(documents, "invoices")},
+		// invoices is inside documents
+		AllowNestedDirs: true,
+		AWSRegion:       "us-east-1",
+		S3Bucket:        "example-bucket",
+	}
+
+	client := &memoryClient{}
+	svc, err := backup.NewService(ctx, cfg, backup.WithClient(client), backup.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	if err := svc.BackupDir(ctx, filepath.Join(documents, "invoices")); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, key := range client.keys() {
+		fmt.Println(withoutTimestamp(key))
+	}
+	// Output:
+	// invoices/invoice-001.txt
+}
+
+func ExampleService_ReloadConfig() {
+	ctx := context.Background()
+
+	dir := exampleDir()
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	documents := filepath.Join(dir, "documents")
+	cfg := &backup.Config{
+		BackupDirs: []string{documents},
+		AWSRegion:  "us-east-1",
+		S3Bucket:   "example-bucket",
+	}
+
+	client := &memoryClient{}
+	svc, err := backup.NewService(ctx, cfg, backup.WithClient(client), backup.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	// Later backups only include the invoices
+	next := *cfg
+	next.BackupDirs = []string{filepath.Join(documents, "invoices")}
+	if err := svc.ReloadConfig(&next); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := svc.Backup(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, key := range client.keys() {
+		fmt.Println(withoutTimestamp(key))
+	}
+	// Output:
+	// invoices/invoice-001.txt
+}
+
+// exampleDir creates a temporary directory holding documents/report.txt and
+// documents/invoices/invoice-001.txt.
+func exampleDir() string {
+	dir, err := os.MkdirTemp("", "backup-example")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	invoices := filepath.Join(dir, "documents", "invoices")
+	if err := os.MkdirAll(invoices, 0750); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "documents", "report.txt"), []byte("report"), 0600); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(invoices, "invoice-001.txt"), []byte("invoice"), 0600); err != nil {
+		log.Fatal(err)
+	}
+	return dir
+}
+
+// withoutTimestamp strips the timestamp prefix shared by all objects of a backup.
+func withoutTimestamp(key string) string {
+	_, rest, _ := strings.Cut(key, "/")
+	return rest
+}
+
+// memoryClient is an API that keeps uploaded object keys in memory instead of sending them to S3.
+type memoryClient struct {
+	mu  sync.Mutex
+	put []string
+}
+
+func (c *memoryClient) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := append([]string(nil), c.put...)
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *memoryClient) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if _, err := io.Copy(io.Discard, params.Body); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put = append(c.put, *params.Key)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (c *memoryClient) HeadBucket(context.Context, *s3.HeadBucketInput, ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (c *memoryClient) GetBucketVersioning(context.Context, *s3.GetBucketVersioningInput, ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	return &s3.GetBucketVersioningOutput{}, nil
+}
+
+func (c *memoryClient) ListObjectsV2(context.Context, *s3.ListObjectsV2Input, ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return &s3.ListObjectsV2Output{}, nil
+}
+
+func (c *memoryClient) DeleteObjects(context.Context, *s3.DeleteObjectsInput, ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func (c *memoryClient) PutBucketIntelligentTieringConfiguration(context.Context, *s3.PutBucketIntelligentTieringConfigurationInput, ...func(*s3.Options)) (*s3.PutBucketIntelligentTieringConfigurationOutput, error) {
+	return &s3.PutBucketIntelligentTieringConfigurationOutput{}, nil
+}
+
+func (c *memoryClient) GetBucketTagging(context.Context, *s3.GetBucketTaggingInput, ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	return &s3.GetBucketTaggingOutput{}, nil
+}
+
+func (c *memoryClient) PutBucketTagging(context.Context, *s3.PutBucketTaggingInput, ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+	return &s3.PutBucketTaggingOutput{}, nil
+}
+
+func (c *memoryClient) CreateMultipartUpload(context.Context, *s3.CreateMultipartUploadInput, ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return nil, fmt.Errorf("multipart uploads are not supported")
+}
+
+func (c *memoryClient) UploadPart(context.Context, *s3.UploadPartInput, ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return nil, fmt.Errorf("multipart uploads are not supported")
+}
+
+func (c *memoryClient) CompleteMultipartUpload(context.Context, *s3.CompleteMultipartUploadInput, ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return nil, fmt.Errorf("multipart uploads are not supported")
+}
+
+func (c *memoryClient) AbortMultipartUpload(context.Context, *s3.AbortMultipartUploadInput, ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
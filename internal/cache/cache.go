@@ -0,0 +1,131 @@
+// Package cache provides a persistent record of previously uploaded files so that
+// unchanged files can be skipped on subsequent backups, even across restarts.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry describes the state of a file at the time it was last uploaded.
+type Entry struct {
+	ModTime time.Time `json:"mtime"`
+	Size    int64     `json:"size"`
+	MD5     string    `json:"md5"`
+}
+
+// Cache maps local file paths to the state they had when last uploaded.
+// It is safe for concurrent use.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// Load reads the cache stored at path.
+// A missing file is not an error and yields an empty cache that will be created on Save.
+func Load(path string) (*Cache, error) {
+	const op = "cache.Load"
+
+	c := &Cache{
+		path:    path,
+		entries: make(map[string]Entry),
+	}
+
+	//nolint:gosec // G304: path comes from the user's cache file configuration
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read cache file %s: %w", op, path, err)
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("%s: failed to decode cache file %s: %w", op, path, err)
+	}
+
+	return c, nil
+}
+
+// Save writes the cache to its file.
+// The file is written to a temporary file first and renamed into place so an
+// interrupted save never leaves a truncated cache behind.
+func (c *Cache) Save() error {
+	const op = "cache.Cache.Save"
+
+	c.mu.Lock()
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode cache: %w", op, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("%s: failed to create temp file: %w", op, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// Only succeeds if the rename below did not happen
+		_ = os.Remove(tmpPath)
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("%s: failed to write temp file %s: %w", op, tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%s: failed to close temp file %s: %w", op, tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("%s: failed to replace cache file %s: %w", op, c.path, err)
+	}
+
+	return nil
+}
+
+// Lookup reports whether filePath is unchanged since it was last uploaded,
+// comparing the cached modification time and size against info.
+// A stale entry is removed so the file is treated as new until Update is called again.
+func (c *Cache) Lookup(filePath string, info fs.FileInfo) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[filePath]
+	if !ok {
+		return false
+	}
+
+	if entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+		delete(c.entries, filePath)
+		return false
+	}
+
+	return true
+}
+
+// Update records that filePath was uploaded with the state described by info and md5.
+func (c *Cache) Update(filePath string, info fs.FileInfo, md5 string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[filePath] = Entry{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		MD5:     md5,
+	}
+}
+
+// Path returns the file the cache is stored in.
+func (c *Cache) Path() string {
+	return c.path
+}
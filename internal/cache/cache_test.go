@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		setup   func(t *testing.T) string
+		wantLen int
+		wantErr bool
+	}{
+		"missing file yields empty cache": {
+			setup: func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "cache.db")
+			},
+		},
+		"existing cache file": {
+			setup: func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "cache.db")
+				content := `{"/data/file.txt":{"mtime":"2025-01-01T00:00:00Z","size":4,"md5":"abc"}}`
+				require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+				return path
+			},
+			wantLen: 1,
+		},
+		"corrupt cache file": {
+			setup: func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "cache.db")
+				require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+				return path
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := tc.setup(t)
+			c, err := Load(path)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, c)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, path, c.Path())
+			assert.Len(t, c.entries, tc.wantLen)
+		})
+	}
+}
+
+func TestCache_SaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filePath := createFile(t, dir, "file.txt", "content")
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+
+	cachePath := filepath.Join(dir, "cache.db")
+	c, err := Load(cachePath)
+	require.NoError(t, err)
+	c.Update(filePath, info, "9a0364b9e99bb480dd25e1f0284c8555")
+	require.NoError(t, c.Save())
+
+	reloaded, err := Load(cachePath)
+	require.NoError(t, err)
+	assert.True(t, reloaded.Lookup(filePath, info), "entry should survive a save and reload")
+	assert.Equal(t, "9a0364b9e99bb480dd25e1f0284c8555", reloaded.entries[filePath].MD5)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "only the tracked file and the cache file should remain")
+}
+
+func TestCache_Lookup(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		modify func(t *testing.T, path string)
+		want   bool
+	}{
+		"unchanged file is a hit": {
+			want: true,
+		},
+		"size change is a miss": {
+			modify: func(t *testing.T, path string) {
+				info, err := os.Stat(path)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(path, []byte("longer content"), 0600))
+				require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+			},
+		},
+		"mtime change is a miss": {
+			modify: func(t *testing.T, path string) {
+				later := time.Now().Add(time.Hour)
+				require.NoError(t, os.Chtimes(path, later, later))
+			},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := createFile(t, t.TempDir(), "file.txt", "content")
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+
+			c := &Cache{entries: make(map[string]Entry)}
+			c.Update(path, info, "md5")
+
+			if tc.modify != nil {
+				tc.modify(t, path)
+			}
+			info, err = os.Stat(path)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, c.Lookup(path, info))
+			if !tc.want {
+				assert.NotContains(t, c.entries, path, "stale entry should be invalidated")
+			}
+		})
+	}
+
+	t.Run("unknown file is a miss", func(t *testing.T) {
+		t.Parallel()
+
+		path := createFile(t, t.TempDir(), "file.txt", "content")
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+
+		c := &Cache{entries: make(map[string]Entry)}
+		assert.False(t, c.Lookup(path, info))
+	})
+}
+
+// createFile creates a file with the given content in dir and returns its path.
+func createFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
@@ -3,6 +3,8 @@ package config
 const (
 	// EnvConfigFile is the path to the YAML configuration file
 	EnvConfigFile = "S3_BACKUP_CONFIG_FILE"
+	// EnvStrictConfig is the environment variable that makes unknown keys in the YAML configuration file an error.
+	EnvStrictConfig = "BACKUP_STRICT_CONFIG"
 
 	// EnvBackupDirs is the environment variable for backup directories.
 	EnvBackupDirs = "BACKUP_DIRS"
@@ -10,9 +12,374 @@ const (
 	EnvRecursive = "BACKUP_RECURSIVE"
 	// EnvCronSchedule is the environment variable for cron schedule.
 	EnvCronSchedule = "BACKUP_CRON_SCHEDULE"
+	// EnvCronMissedJob is the environment variable for the policy applied to scheduled backups missed while
+	// the system was suspended.
+	EnvCronMissedJob = "BACKUP_CRON_MISSED_JOB"
+	// EnvCronPrecision is the environment variable for the precision of the cron schedule.
+	EnvCronPrecision = "BACKUP_CRON_PRECISION"
+	// EnvCronWarnLongIntervalHours is the environment variable for how many hours away the next scheduled
+	// backup may be before a warning is logged on startup.
+	EnvCronWarnLongIntervalHours = "BACKUP_CRON_WARN_LONG_INTERVAL_HOURS"
+	// EnvScheduleJitterSeconds is the environment variable for the longest random delay in seconds before
+	// a scheduled backup starts.
+	EnvScheduleJitterSeconds = "BACKUP_SCHEDULE_JITTER_SECONDS"
+	// EnvCronMaxConcurrentRuns is the environment variable for how many scheduled backups may run at the same time.
+	EnvCronMaxConcurrentRuns = "BACKUP_CRON_MAX_CONCURRENT_RUNS"
+	// EnvJobTimeoutSeconds is the environment variable for how many seconds a scheduled backup may run.
+	EnvJobTimeoutSeconds = "BACKUP_JOB_TIMEOUT_SECONDS"
+	// EnvRunOnStart is the environment variable that runs a backup as soon as the scheduler starts.
+	EnvRunOnStart = "BACKUP_RUN_ON_START"
+	// EnvDisableSchedulerOnStartupFailure is the environment variable that keeps the scheduler from
+	// starting if the backup run on start fails.
+	EnvDisableSchedulerOnStartupFailure = "BACKUP_DISABLE_SCHEDULER_ON_STARTUP_FAILURE"
 
 	// EnvAWSRegion is the environment variable for AWS region.
 	EnvAWSRegion = "AWS_REGION"
 	// EnvS3Bucket is the environment variable for S3 bucket name.
 	EnvS3Bucket = "S3_BUCKET"
+	// EnvS3EndpointRegion is the environment variable for the region used to sign S3 requests, if it differs from AWS_REGION.
+	EnvS3EndpointRegion = "S3_ENDPOINT_REGION"
+
+	// EnvUsePathStyle is the environment variable that addresses buckets in the URL path, as MinIO requires.
+	EnvUsePathStyle = "BACKUP_USE_PATH_STYLE"
+	// EnvTransferAcceleration is the environment variable that sends requests to the S3 Transfer Acceleration endpoint.
+	EnvTransferAcceleration = "BACKUP_S3_TRANSFER_ACCELERATION"
+
+	// EnvHTTPProxy is the environment variable for the proxy AWS requests are sent through,
+	// overriding HTTP_PROXY and HTTPS_PROXY.
+	EnvHTTPProxy = "BACKUP_HTTP_PROXY"
+	// EnvNoProxy is the environment variable for the comma-separated hosts that bypass EnvHTTPProxy.
+	EnvNoProxy = "BACKUP_NO_PROXY"
+
+	// EnvTLSCACertFile is the environment variable for a PEM file of additional certificate authorities
+	// trusted for AWS requests, e.g. for a MinIO cluster with a self-signed certificate.
+	EnvTLSCACertFile = "BACKUP_TLS_CA_CERT_FILE"
+
+	// EnvPreserveAbsolutePath is the environment variable that keeps the full absolute file path in S3 keys.
+	EnvPreserveAbsolutePath = "BACKUP_PRESERVE_ABSOLUTE_PATH"
+
+	// EnvSkipLockedFiles is the environment variable that skips files another process holds an exclusive lock on.
+	EnvSkipLockedFiles = "BACKUP_SKIP_LOCKED_FILES"
+
+	// EnvExcludeHidden is the environment variable that skips dotfiles and dot-directories.
+	EnvExcludeHidden = "BACKUP_EXCLUDE_HIDDEN"
+
+	// EnvAllowNestedDirs is the environment variable that allows a backup directory inside another one.
+	EnvAllowNestedDirs = "BACKUP_ALLOW_NESTED_DIRS"
+
+	// EnvRequireLocalFS is the environment variable that rejects backup directories on network filesystems.
+	EnvRequireLocalFS = "BACKUP_REQUIRE_LOCAL_FS"
+	// EnvSkipUnchangedDirs is the environment variable that skips directories not modified since their last backup.
+	EnvSkipUnchangedDirs = "BACKUP_SKIP_UNCHANGED_DIRS"
+	// EnvMaxWalkDepth is the environment variable for how many directory levels recursive backups descend into.
+	EnvMaxWalkDepth = "BACKUP_MAX_WALK_DEPTH"
+	// EnvEmptyDirPlaceholder is the environment variable that uploads a .keep object for every directory without files.
+	EnvEmptyDirPlaceholder = "BACKUP_EMPTY_DIR_PLACEHOLDER"
+
+	// EnvArchiveMode is the environment variable for the archive mode used to upload backup directories.
+	EnvArchiveMode = "BACKUP_ARCHIVE_MODE"
+
+	// EnvTempDir is the environment variable for the directory temporary files such as archives are written to.
+	EnvTempDir = "BACKUP_TEMP_DIR"
+
+	// EnvIncremental is the environment variable that enables skipping files unchanged since the last upload.
+	EnvIncremental = "BACKUP_INCREMENTAL"
+
+	// EnvCacheFile is the environment variable for the path of the incremental backup cache file.
+	EnvCacheFile = "BACKUP_CACHE_FILE"
+
+	// EnvRequesterPays is the environment variable that sends x-amz-request-payer for requester-pays buckets.
+	EnvRequesterPays = "BACKUP_S3_REQUESTER_PAYS"
+
+	// EnvFileMetadata is the environment variable that attaches file information to uploads as S3 object metadata.
+	EnvFileMetadata = "BACKUP_OBJECT_METADATA_FILE_INFO"
+
+	// EnvContentDisposition is the environment variable for the Content-Disposition type of uploaded objects.
+	EnvContentDisposition = "BACKUP_S3_CONTENT_DISPOSITION"
+
+	// EnvConcurrency is the environment variable for the number of files uploaded at the same time.
+	EnvConcurrency = "BACKUP_CONCURRENCY"
+
+	// EnvConcurrencyPerDir is the environment variable for the number of files of a single backup
+	// directory uploaded at the same time.
+	EnvConcurrencyPerDir = "BACKUP_CONCURRENCY_PER_DIR"
+
+	// EnvMultipartUpload is the environment variable that enables multipart uploads for large files.
+	EnvMultipartUpload = "BACKUP_MULTIPART_UPLOAD"
+
+	// EnvUploadPartSizeMB is the environment variable for the multipart upload part size in MiB.
+	EnvUploadPartSizeMB = "BACKUP_UPLOAD_PART_SIZE_MB"
+
+	// EnvMultipartConcurrency is the environment variable for the number of parts of a file uploaded at the same time.
+	EnvMultipartConcurrency = "BACKUP_S3_MULTIPART_CONCURRENCY"
+
+	// EnvMaxObjectKeyLength is the environment variable for the longest S3 object key in bytes.
+	EnvMaxObjectKeyLength = "BACKUP_MAX_OBJECT_KEY_LENGTH"
+
+	// EnvObjectKeySeparator is the environment variable for the separator used in object keys instead of "/".
+	EnvObjectKeySeparator = "BACKUP_OBJECT_KEY_SEPARATOR"
+
+	// EnvObjectPrefixDateOnly is the environment variable that prefixes object keys with the date of their backup only.
+	EnvObjectPrefixDateOnly = "BACKUP_S3_OBJECT_PREFIX_DATE_ONLY"
+
+	// EnvPresignAfterUpload is the environment variable that logs a pre-signed GET URL for every uploaded object.
+	EnvPresignAfterUpload = "BACKUP_S3_PRESIGN_AFTER_UPLOAD"
+
+	// EnvPresignExpiryHours is the environment variable for how many hours pre-signed URLs stay valid.
+	EnvPresignExpiryHours = "BACKUP_S3_PRESIGN_EXPIRY_HOURS"
+
+	// EnvUpdateLatestPointer is the environment variable that makes each successful backup overwrite _latest/pointer.json.
+	EnvUpdateLatestPointer = "BACKUP_UPDATE_LATEST_POINTER"
+
+	// EnvKMSKeyID is the environment variable for the AWS KMS key used to encrypt uploads with SSE-KMS.
+	EnvKMSKeyID = "BACKUP_S3_KMS_KEY_ID"
+
+	// EnvKMSContext is the environment variable for the SSE-KMS encryption context in key1=value1,key2=value2 form.
+	EnvKMSContext = "BACKUP_S3_KMS_CONTEXT"
+
+	// EnvStorageClassMap is the environment variable for the S3 storage class of each file extension in
+	// ext1=CLASS1,ext2=CLASS2 form.
+	EnvStorageClassMap = "BACKUP_S3_STORAGE_CLASS_MAP"
+
+	// EnvObjectLockMode is the environment variable for the S3 Object Lock mode applied to uploads.
+	EnvObjectLockMode = "BACKUP_S3_OBJECT_LOCK_MODE"
+
+	// EnvObjectLockRetainDays is the environment variable for how many days uploads are locked.
+	EnvObjectLockRetainDays = "BACKUP_S3_OBJECT_LOCK_RETAIN_DAYS"
+
+	// EnvChecksumAlgorithm is the environment variable for the integrity checksum sent with each upload.
+	EnvChecksumAlgorithm = "BACKUP_S3_CHECKSUM_ALGORITHM"
+
+	// EnvCostPerPutUSD is the environment variable for the S3 PUT request price used by backup estimates.
+	EnvCostPerPutUSD = "BACKUP_COST_PER_PUT_USD"
+
+	// EnvRequireMinFiles is the environment variable for the minimum number of files a backup must contain.
+	EnvRequireMinFiles = "BACKUP_REQUIRE_MIN_FILES"
+
+	// EnvRequireMinBytes is the environment variable for the minimum total size in bytes a backup must contain.
+	EnvRequireMinBytes = "BACKUP_REQUIRE_MIN_BYTES"
+
+	// EnvMaxErrors is the environment variable for the number of consecutive upload failures that abort a backup.
+	EnvMaxErrors = "BACKUP_MAX_ERRORS"
+
+	// EnvProgressLogEveryNFiles is the environment variable for the number of uploads between backup progress logs.
+	EnvProgressLogEveryNFiles = "BACKUP_PROGRESS_LOG_EVERY_N_FILES"
+
+	// EnvReportFile is the environment variable for the path of the JSON report written after each backup.
+	EnvReportFile = "BACKUP_REPORT_FILE"
+
+	// EnvCloudWatchMetricsNamespace is the environment variable for the CloudWatch namespace backup metrics are published to.
+	EnvCloudWatchMetricsNamespace = "BACKUP_CLOUD_WATCH_METRICS_NAMESPACE"
+
+	// EnvRetentionDays is the environment variable for the number of days backups are kept before being pruned.
+	EnvRetentionDays = "BACKUP_RETENTION_DAYS"
+
+	// EnvUserAgent is the environment variable for the suffix appended to the AWS SDK User-Agent.
+	EnvUserAgent = "BACKUP_USER_AGENT"
+
+	// EnvVersioningCheck is the environment variable that enables the bucket versioning check on startup.
+	EnvVersioningCheck = "BACKUP_OBJECT_VERSIONING_CHECK"
+
+	// EnvPreflightCheck is the environment variable that checks the bucket is accessible when the service is created.
+	EnvPreflightCheck = "BACKUP_PREFLIGHT_CHECK"
+
+	// EnvRequireVersioning is the environment variable that makes disabled bucket versioning a startup error.
+	EnvRequireVersioning = "BACKUP_REQUIRE_VERSIONING"
+
+	// EnvConfigureIntelligentTiering is the environment variable that configures the S3 Intelligent-Tiering
+	// archive tiers of the bucket on startup.
+	EnvConfigureIntelligentTiering = "BACKUP_S3_CONFIGURE_INTELLIGENT_TIERING"
+
+	// EnvIntelligentTieringArchiveAccessDays is the environment variable for the days without access after
+	// which Intelligent-Tiering objects move to the Archive Access tier.
+	EnvIntelligentTieringArchiveAccessDays = "BACKUP_S3_INTELLIGENT_TIERING_ARCHIVE_ACCESS_DAYS"
+
+	// EnvIntelligentTieringDeepArchiveAccessDays is the environment variable for the days without access after
+	// which Intelligent-Tiering objects move to the Deep Archive Access tier.
+	EnvIntelligentTieringDeepArchiveAccessDays = "BACKUP_S3_INTELLIGENT_TIERING_DEEP_ARCHIVE_ACCESS_DAYS"
+
+	// EnvTagBucket is the environment variable that tags the bucket as managed by s3-backup on startup.
+	EnvTagBucket = "BACKUP_TAG_BUCKET"
+
+	// EnvLogLevel is the environment variable for the global log level.
+	EnvLogLevel = "LOG_LEVEL"
+
+	// EnvLogLevelConfig is the environment variable for the log level of the config package.
+	EnvLogLevelConfig = "BACKUP_LOG_LEVEL_CONFIG"
+
+	// EnvLogLevelS3 is the environment variable for the log level of the s3 package.
+	EnvLogLevelS3 = "BACKUP_LOG_LEVEL_S3"
+
+	// EnvLogSource is the environment variable that adds the source file and line to each log record.
+	EnvLogSource = "LOG_SOURCE"
+
+	// EnvDryRunOutputFile is the environment variable for a JSON file the objects planned by --dry-run are written to.
+	EnvDryRunOutputFile = "BACKUP_DRY_RUN_OUTPUT_FILE"
+
+	// EnvOutputFormat is the environment variable for the default of the --output flag, human or json.
+	EnvOutputFormat = "BACKUP_OUTPUT_FORMAT"
+
+	// EnvLogFile is the environment variable for a file that log output is mirrored to.
+	EnvLogFile = "BACKUP_LOG_FILE"
+
+	// EnvLogMaxSizeMB is the environment variable for the size in MiB at which the log file is rotated.
+	EnvLogMaxSizeMB = "BACKUP_LOG_MAX_SIZE_MB"
+
+	// EnvLogMaxBackups is the environment variable for the number of rotated log files that are kept.
+	EnvLogMaxBackups = "BACKUP_LOG_MAX_BACKUPS"
+)
+
+const (
+	// envXDGConfigHome is the XDG base directory for user-specific configuration files.
+	envXDGConfigHome = "XDG_CONFIG_HOME"
+
+	// envXDGConfigDirs is the colon-separated list of XDG base directories for system-wide configuration files.
+	envXDGConfigDirs = "XDG_CONFIG_DIRS"
+
+	// defaultXDGConfigDirs is used when envXDGConfigDirs is not set.
+	defaultXDGConfigDirs = "/etc/xdg"
+
+	// xdgConfigFile is the config file path relative to an XDG config directory.
+	xdgConfigFile = "s3-backup/config.yaml"
+)
+
+// ConfigFileStdin is the EnvConfigFile value that reads the YAML configuration from stdin.
+const ConfigFileStdin = "-"
+
+// DefaultCronSchedule is an example schedule (daily at 2 AM) shown in help output.
+// It is never applied implicitly: without a configured schedule a single backup runs.
+// It is a variable so builds can override it, e.g.
+// -ldflags "-X 's3-backup/internal/config.DefaultCronSchedule=0 * * * *'".
+var DefaultCronSchedule = "0 2 * * *"
+
+// DefaultCronWarnLongIntervalHours is used when EnvCronWarnLongIntervalHours is not set.
+const DefaultCronWarnLongIntervalHours = 24
+
+// DefaultCronMaxConcurrentRuns is used when EnvCronMaxConcurrentRuns is not set; scheduled backups never overlap.
+const DefaultCronMaxConcurrentRuns = 1
+
+const (
+	// DefaultLogMaxSizeMB is used when EnvLogMaxSizeMB is not set.
+	DefaultLogMaxSizeMB = 100
+
+	// DefaultLogMaxBackups is used when EnvLogMaxBackups is not set.
+	DefaultLogMaxBackups = 3
+)
+
+// DefaultProgressLogEveryNFiles is used when EnvProgressLogEveryNFiles is not set.
+const DefaultProgressLogEveryNFiles = 100
+
+// DefaultConcurrency is used when EnvConcurrency is not set; files are uploaded one at a time.
+const DefaultConcurrency = 1
+
+// DefaultCostPerPutUSD is the price of a single S3 Standard PUT request ($0.005 per 1,000 requests).
+const DefaultCostPerPutUSD = 0.000005
+
+const (
+	// DefaultUploadPartSizeMB is the multipart upload part size used when EnvUploadPartSizeMB is not set.
+	DefaultUploadPartSizeMB = MinUploadPartSizeMB
+
+	// MinUploadPartSizeMB is the smallest part size S3 accepts for all but the last part.
+	MinUploadPartSizeMB = 5
+
+	// MaxUploadPartSizeMB is the largest part size S3 accepts (5 GiB).
+	MaxUploadPartSizeMB = 5120
+)
+
+const (
+	// DefaultMultipartConcurrency is the number of parts of a file uploaded at the same time
+	// when EnvMultipartConcurrency is not set.
+	DefaultMultipartConcurrency = 5
+
+	// MaxMultipartConcurrency is the largest accepted value of EnvMultipartConcurrency.
+	MaxMultipartConcurrency = 100
+)
+
+// DefaultObjectKeySeparator separates the timestamp prefix and the directories of object keys
+// when EnvObjectKeySeparator is not set.
+const DefaultObjectKeySeparator = "/"
+
+// MaxObjectKeyLength is the longest object key in bytes AWS S3 accepts, and the default
+// when EnvMaxObjectKeyLength is not set.
+const MaxObjectKeyLength = 1024
+
+const (
+	// DefaultPresignExpiryHours is how long pre-signed URLs stay valid when EnvPresignExpiryHours is not set.
+	DefaultPresignExpiryHours = 24
+
+	// MaxPresignExpiryHours is the longest validity of a pre-signed URL SigV4 accepts (7 days).
+	MaxPresignExpiryHours = 168
+)
+
+const (
+	// DefaultIntelligentTieringArchiveAccessDays is used when EnvIntelligentTieringArchiveAccessDays is not set,
+	// and is also the minimum S3 accepts.
+	DefaultIntelligentTieringArchiveAccessDays = 90
+
+	// DefaultIntelligentTieringDeepArchiveAccessDays is used when EnvIntelligentTieringDeepArchiveAccessDays is
+	// not set, and is also the minimum S3 accepts.
+	DefaultIntelligentTieringDeepArchiveAccessDays = 180
+
+	// MaxIntelligentTieringAccessDays is the most days without access S3 accepts for either archive tier (2 years).
+	MaxIntelligentTieringAccessDays = 730
+)
+
+// DefaultCacheFileName is the name of the incremental backup cache file created in the
+// system temp directory when EnvCacheFile is not set.
+const DefaultCacheFileName = "s3-backup-cache.db"
+
+const (
+	// ObjectLockModeNone uploads objects without an Object Lock retention period.
+	ObjectLockModeNone = ""
+
+	// ObjectLockModeGovernance locks objects, but users with special permissions can remove the lock.
+	ObjectLockModeGovernance = "GOVERNANCE"
+
+	// ObjectLockModeCompliance locks objects so no user, including the root user, can delete them
+	// until the retention period ends.
+	ObjectLockModeCompliance = "COMPLIANCE"
+)
+
+const (
+	// CronMissedJobSkip logs missed scheduled backups and waits for the next scheduled run.
+	CronMissedJobSkip = "skip"
+
+	// CronMissedJobRunImmediately runs a missed scheduled backup as soon as it is detected.
+	CronMissedJobRunImmediately = "run_immediately"
+)
+
+const (
+	// CronPrecisionMinute expects standard five-field cron schedules.
+	CronPrecisionMinute = "minute"
+
+	// CronPrecisionSecond expects six-field cron schedules whose first field is the second.
+	CronPrecisionSecond = "second"
+)
+
+const (
+	// ArchiveModeNone uploads every file as an individual S3 object.
+	ArchiveModeNone = ""
+
+	// ArchiveModeTarGz packs each backup directory into a single gzip-compressed tar archive.
+	ArchiveModeTarGz = "tar.gz"
+)
+
+const (
+	// ChecksumNone uploads objects without a client-computed checksum.
+	ChecksumNone = ""
+
+	// ChecksumMD5 sends the legacy Content-MD5 header.
+	ChecksumMD5 = "MD5"
+
+	// ChecksumCRC32 sends an x-amz-checksum-crc32 header.
+	ChecksumCRC32 = "CRC32"
+
+	// ChecksumCRC32C sends an x-amz-checksum-crc32c header.
+	ChecksumCRC32C = "CRC32C"
+
+	// ChecksumSHA1 sends an x-amz-checksum-sha1 header.
+	ChecksumSHA1 = "SHA1"
+
+	// ChecksumSHA256 sends an x-amz-checksum-sha256 header.
+	ChecksumSHA256 = "SHA256"
 )
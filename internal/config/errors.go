@@ -1,12 +1,32 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrNoBackupDirs is returned when no backup directories are configured.
 	ErrNoBackupDirs = errors.New("no backup directories configured")
 	// ErrInvalidDir is returned when a directory does not exist or is not a directory.
 	ErrInvalidDir = errors.New("directory does not exist or is not a directory")
+	// ErrNestedBackupDirs is returned when a backup directory is inside another backup directory.
+	ErrNestedBackupDirs = errors.New("backup directory is inside another backup directory")
+	// ErrDuplicateBackupDirs is returned when the same backup directory is configured more than once.
+	ErrDuplicateBackupDirs = errors.New("backup directory is configured more than once")
+	// ErrNetworkMount is returned when a backup directory is on a network filesystem and local filesystems are required.
+	ErrNetworkMount = errors.New("backup directory is on a network filesystem")
+	// ErrInvalidMaxWalkDepth is returned when the directory depth limit is negative.
+	ErrInvalidMaxWalkDepth = errors.New("invalid max walk depth")
+	// ErrDirectoryNotReadable is returned when a directory exists but cannot be read by the current user.
+	ErrDirectoryNotReadable = errors.New("directory is not readable")
+	// ErrTempDirNotWritable is returned when temporary files cannot be created in the temp directory.
+	ErrTempDirNotWritable = errors.New("temp directory is not writable")
+	// ErrNoGlobMatches is returned when a backup directory pattern matches no directory.
+	// It is logged as a warning and does not fail configuration loading.
+	ErrNoGlobMatches = errors.New("pattern matched no directories")
+	// ErrStdinIsTerminal is returned when the config file is read from stdin but stdin is not redirected.
+	ErrStdinIsTerminal = errors.New("stdin is a terminal, pipe the config file into it")
 
 	// ErrMissingAWSRegion is returned when AWS region is not configured.
 	ErrMissingAWSRegion = errors.New("missing AWS region")
@@ -14,6 +34,132 @@ var (
 	ErrInvalidAWSRegion = errors.New("invalid AWS region format")
 	// ErrMissingS3BucketName is returned when S3 bucket name is not configured.
 	ErrMissingS3BucketName = errors.New("missing S3 bucket name")
+	// ErrInvalidS3BucketName is returned when the S3 bucket name breaks the S3 bucket naming rules.
+	ErrInvalidS3BucketName = errors.New("invalid S3 bucket name")
+	// ErrInvalidCronMissedJob is returned when the missed cron job policy is not supported.
+	ErrInvalidCronMissedJob = errors.New("invalid missed cron job policy")
+	// ErrInvalidCronPrecision is returned when the cron precision is not supported.
+	ErrInvalidCronPrecision = errors.New("invalid cron precision")
+	// ErrInvalidCronSchedule is returned when the cron schedule does not have the number of fields
+	// its precision requires.
+	ErrInvalidCronSchedule = errors.New("invalid cron schedule")
+	// ErrInvalidCronWarnInterval is returned when the long cron interval warning threshold is negative.
+	ErrInvalidCronWarnInterval = errors.New("invalid cron long interval warning threshold")
+	// ErrInvalidScheduleJitter is returned when the random delay before scheduled backups is negative.
+	ErrInvalidScheduleJitter = errors.New("invalid schedule jitter")
+	// ErrInvalidCronMaxConcurrentRuns is returned when the limit of concurrent scheduled backups is negative.
+	ErrInvalidCronMaxConcurrentRuns = errors.New("invalid cron max concurrent runs")
+	// ErrInvalidJobTimeout is returned when the timeout of scheduled backups is negative.
+	ErrInvalidJobTimeout = errors.New("invalid job timeout")
+	// ErrInvalidArchiveMode is returned when the archive mode is not supported.
+	ErrInvalidArchiveMode = errors.New("invalid archive mode")
+	// ErrInvalidPartSize is returned when the multipart upload part size is outside the range S3 accepts.
+	ErrInvalidPartSize = errors.New("invalid upload part size")
+	// ErrInvalidMultipartConcurrency is returned when the multipart upload concurrency is out of range.
+	ErrInvalidMultipartConcurrency = errors.New("invalid multipart upload concurrency")
+	// ErrInvalidMaxKeyLength is returned when the object key length limit is outside the range S3 accepts.
+	ErrInvalidMaxKeyLength = errors.New("invalid max object key length")
+	// ErrInvalidIntelligentTieringDays is returned when the Intelligent-Tiering archive tiers are configured
+	// with days S3 does not accept.
+	ErrInvalidIntelligentTieringDays = errors.New("invalid intelligent tiering days")
+	// ErrInvalidKeySeparator is returned when the object key separator cannot be told apart from the timestamp prefix.
+	ErrInvalidKeySeparator = errors.New("invalid object key separator")
+	// ErrInvalidPresignExpiry is returned when the validity of pre-signed URLs is outside the range SigV4 accepts.
+	ErrInvalidPresignExpiry = errors.New("invalid pre-signed URL expiry")
+	// ErrInvalidChecksumAlgorithm is returned when the upload checksum algorithm is not supported.
+	ErrInvalidChecksumAlgorithm = errors.New("invalid checksum algorithm")
+	// ErrInvalidStorageClass is returned when the storage class map names an unknown storage class or an empty extension.
+	ErrInvalidStorageClass = errors.New("invalid storage class")
+
+	// ErrInvalidMinimum is returned when a minimum file count or size is negative.
+	ErrInvalidMinimum = errors.New("invalid backup minimum")
+
+	// ErrInvalidMaxErrors is returned when the consecutive upload failure limit is negative.
+	ErrInvalidMaxErrors = errors.New("invalid max errors")
+
+	// ErrInvalidProgressInterval is returned when the number of uploads between progress logs is negative.
+	ErrInvalidProgressInterval = errors.New("invalid progress log interval")
+
+	// ErrInvalidConcurrency is returned when an upload concurrency limit is negative.
+	ErrInvalidConcurrency = errors.New("invalid upload concurrency")
+	// ErrInvalidRetentionDays is returned when the retention period is negative.
+	ErrInvalidRetentionDays = errors.New("invalid retention days")
+
+	// ErrKMSContextWithoutKeyID is returned when a KMS encryption context is configured without a KMS key ID.
+	ErrKMSContextWithoutKeyID = errors.New("KMS encryption context requires a KMS key ID")
+
+	// ErrInvalidObjectLockMode is returned when the Object Lock mode is not supported.
+	ErrInvalidObjectLockMode = errors.New("invalid object lock mode")
+	// ErrInvalidObjectLockRetainDays is returned when the Object Lock retention period is not positive.
+	ErrInvalidObjectLockRetainDays = errors.New("invalid object lock retain days")
+	// ErrIncompleteLockConfiguration is returned when only one of the Object Lock mode and retention period is set.
+	ErrIncompleteLockConfiguration = errors.New("object lock mode and retain days must be set together")
+
+	// ErrInvalidUserAgent is returned when the User-Agent suffix contains unsupported characters.
+	ErrInvalidUserAgent = errors.New("invalid user agent")
+
+	// ErrInvalidContentDisposition is returned when the Content-Disposition type is not a valid token.
+	ErrInvalidContentDisposition = errors.New("invalid content disposition")
+
+	// ErrInvalidCloudWatchNamespace is returned when the CloudWatch metrics namespace is not accepted by CloudWatch.
+	ErrInvalidCloudWatchNamespace = errors.New("invalid CloudWatch metrics namespace")
+
+	// ErrInvalidProxyURL is returned when the HTTP proxy is not an absolute URL.
+	ErrInvalidProxyURL = errors.New("invalid HTTP proxy URL")
+
+	// ErrInvalidCACertFile is returned when the CA certificate file cannot be read or contains no PEM certificate.
+	ErrInvalidCACertFile = errors.New("invalid CA certificate file")
+
+	// ErrInvalidEnvValue is returned when an environment variable cannot be parsed.
+	ErrInvalidEnvValue = errors.New("invalid environment variable value")
+
+	// ErrInvalidCostPerPut is returned when the S3 PUT request price is negative.
+	ErrInvalidCostPerPut = errors.New("invalid cost per PUT request")
+
+	// ErrIncompatibleOptions is returned when the configuration enables options that cannot be used together.
+	// Each violated combination has its own error wrapping it, e.g. ErrAccelerationWithPathStyle.
+	ErrIncompatibleOptions = errors.New("incompatible options")
+	// ErrAccelerationWithPathStyle is returned when transfer acceleration is enabled together with
+	// path-style addressing, which the accelerate endpoint does not support.
+	ErrAccelerationWithPathStyle = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvTransferAcceleration, EnvUsePathStyle)
+	// ErrArchiveWithIncremental is returned when archives are enabled together with incremental backups,
+	// which only skip unchanged files uploaded individually.
+	ErrArchiveWithIncremental = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvArchiveMode, EnvIncremental)
+	// ErrArchiveWithFileMetadata is returned when archives are enabled together with file metadata,
+	// which is only attached to files uploaded individually.
+	ErrArchiveWithFileMetadata = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvArchiveMode, EnvFileMetadata)
+	// ErrArchiveWithSkipUnchangedDirs is returned when archives are enabled together with skipping
+	// unchanged directories, which would upload empty archives for them.
+	ErrArchiveWithSkipUnchangedDirs = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvArchiveMode, EnvSkipUnchangedDirs)
+	// ErrArchiveWithEmptyDirPlaceholder is returned when archives are enabled together with empty directory
+	// placeholders, which are only uploaded next to files uploaded individually.
+	ErrArchiveWithEmptyDirPlaceholder = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvArchiveMode, EnvEmptyDirPlaceholder)
+	// ErrRetentionWithIncremental is returned when retention is enabled together with incremental backups,
+	// since pruning an old backup would delete the only copy of files skipped as unchanged since then.
+	ErrRetentionWithIncremental = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvRetentionDays, EnvIncremental)
+	// ErrRetentionWithSkipUnchangedDirs is returned when retention is enabled together with skipping
+	// unchanged directories, since pruning an old backup would delete the only copy of those directories.
+	ErrRetentionWithSkipUnchangedDirs = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvRetentionDays, EnvSkipUnchangedDirs)
+	// ErrMinFilesWithSkipUnchangedDirs is returned when a minimum file count is required together with
+	// skipping unchanged directories, since skipped directories contribute no files to the count.
+	ErrMinFilesWithSkipUnchangedDirs = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvRequireMinFiles, EnvSkipUnchangedDirs)
+	// ErrMinBytesWithSkipUnchangedDirs is returned when a minimum total size is required together with
+	// skipping unchanged directories, since skipped directories contribute no bytes to the total.
+	ErrMinBytesWithSkipUnchangedDirs = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvRequireMinBytes, EnvSkipUnchangedDirs)
+	// ErrLatestPointerWithSkipUnchangedDirs is returned when the latest pointer is updated together with
+	// skipping unchanged directories, since the pointer would name a backup missing the skipped directories.
+	ErrLatestPointerWithSkipUnchangedDirs = fmt.Errorf("%w: %s cannot be used with %s",
+		ErrIncompatibleOptions, EnvUpdateLatestPointer, EnvSkipUnchangedDirs)
+
 	// ErrInvalidConfigFile is returned when configuration file is invalid.
 	ErrInvalidConfigFile = errors.New("invalid configuration file")
 )
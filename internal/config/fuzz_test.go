@@ -71,7 +71,7 @@ func FuzzLoadFromEnv(f *testing.F) {
 		t.Setenv(EnvCronSchedule, cronSchedule)
 
 		cfg := &Config{}
-		loadFromEnv(cfg)
+		_ = loadFromEnv(cfg)
 
 		if cfg.BackupDirs != nil {
 			for _, dir := range cfg.BackupDirs {
@@ -123,12 +123,9 @@ func FuzzValidateS3Bucket(f *testing.F) {
 	f.Add("bucket\x00name")
 
 	f.Fuzz(func(t *testing.T, bucket string) {
-		cfg := &Config{
-			AWSRegion:  "us-west-2",
-			S3Bucket:   bucket,
-			BackupDirs: []string{t.TempDir()},
+		err := validateS3BucketName(bucket)
+		if err == nil && (len(bucket) < 3 || len(bucket) > 63 || strings.ToLower(bucket) != bucket) {
+			t.Errorf("validateS3BucketName(%q) accepted a name S3 rejects", bucket)
 		}
-
-		_ = validateConfig(cfg)
 	})
 }
@@ -1,17 +1,36 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// unknownFieldPattern extracts the key from the errors yaml.v3 reports for unknown fields,
+// e.g. "line 2: field backup_dir not found in type config.Config".
+var unknownFieldPattern = regexp.MustCompile(`field (\S+) not found in type`)
+
 // loadFromYaml loads configuration from a YAML file into the provided target struct.
 // Returns nil error if file doesn't exist (allows fallback to env vars).
-func loadFromYaml(filePath string, target any) error {
+// If filePath is ConfigFileStdin, the configuration is read from stdin instead.
+// Unknown keys are logged as warnings, or rejected with ErrInvalidConfigFile if strict is set.
+func loadFromYaml(filePath string, target any, strict bool) error {
 	const op = "config.loadFromYaml"
 
+	if filePath == ConfigFileStdin {
+		if err := loadFromStdin(target, strict); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
 	// If file doesn't exist, return nil to allow env var fallback
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return nil
@@ -23,9 +42,74 @@ func loadFromYaml(filePath string, target any) error {
 		return fmt.Errorf("%s: failed to read file: %w", op, err)
 	}
 
-	if err := yaml.Unmarshal(data, target); err != nil {
-		return fmt.Errorf("%s: failed to unmarshal YAML: %w", op, err)
+	if err := decodeYAML(data, target, strict); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// loadFromStdin decodes YAML configuration from stdin into the provided target struct.
+// Empty input leaves target unchanged. It refuses to read from a terminal (or a
+// character device such as /dev/null in tests) so it never blocks waiting for input.
+func loadFromStdin(target any, strict bool) error {
+	const op = "config.loadFromStdin"
+
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return fmt.Errorf("%s: failed to stat stdin: %w", op, err)
+	}
+	if info.Mode()&os.ModeCharDevice != 0 {
+		return fmt.Errorf("%s: %w", op, ErrStdinIsTerminal)
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("%s: failed to read stdin: %w", op, err)
 	}
 
+	if err := decodeYAML(data, target, strict); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// decodeYAML decodes data into target. Keys that match no field of target do not stop
+// decoding: they are logged as warnings, so config files written for newer versions still
+// load, or returned as ErrInvalidConfigFile if strict is set. Empty data leaves target unchanged.
+func decodeYAML(data []byte, target any, strict bool) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	err := dec.Decode(target)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+
+	var unknown, other []string
+	for _, msg := range typeErr.Errors {
+		if match := unknownFieldPattern.FindStringSubmatch(msg); match != nil {
+			unknown = append(unknown, match[1])
+		} else {
+			other = append(other, msg)
+		}
+	}
+	if len(other) > 0 {
+		return fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+
+	if strict {
+		return fmt.Errorf("%w: unknown keys %s (unset %s to ignore them)", ErrInvalidConfigFile,
+			strings.Join(unknown, ", "), EnvStrictConfig)
+	}
+	for _, key := range unknown {
+		slog.Warn("unknown config key", "key", key)
+	}
 	return nil
 }
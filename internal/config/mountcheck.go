@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// networkFSTypes are the filesystem types of network mounts, as reported by /proc/mounts on
+// Linux and statfs on macOS.
+var networkFSTypes = []string{"nfs", "nfs4", "cifs", "smb3", "smbfs", "afpfs", "webdav", "fuse.sshfs"}
+
+// isNetworkFSType reports whether fstype is the type of a network filesystem.
+func isNetworkFSType(fstype string) bool {
+	return slices.Contains(networkFSTypes, strings.ToLower(fstype))
+}
+
+// checkNetworkMount warns when dir is on a network filesystem, which can make backups slow or
+// partial if the mount disconnects. With requireLocalFS it returns ErrNetworkMount instead.
+// fsType returns the type of the filesystem holding a directory; if it fails, dir is assumed local.
+func checkNetworkMount(dir string, requireLocalFS bool, fsType func(dir string) (string, error)) error {
+	fstype, err := fsType(dir)
+	if err != nil {
+		slog.Debug("could not detect filesystem type", "dir", dir, "error", err)
+		return nil
+	}
+	if !isNetworkFSType(fstype) {
+		return nil
+	}
+
+	if requireLocalFS {
+		return fmt.Errorf("%w: %s is on %s (unset %s to allow)", ErrNetworkMount, dir, fstype, EnvRequireLocalFS)
+	}
+	slog.Warn("network mount detected", "dir", dir, "fstype", fstype)
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build darwin
+
+package config
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// filesystemType returns the type of the filesystem holding dir, e.g. "apfs" or "smbfs".
+func filesystemType(dir string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return "", fmt.Errorf("failed to stat filesystem of %s: %w", dir, err)
+	}
+
+	name := make([]byte, 0, len(st.Fstypename))
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return string(name), nil
+}
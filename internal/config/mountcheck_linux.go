@@ -0,0 +1,94 @@
+//go:build linux
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// mountsFile lists the mounted filesystems of the current process.
+const mountsFile = "/proc/mounts"
+
+// filesystemType returns the type of the filesystem holding dir, e.g. "ext4" or "nfs4",
+// from the mount in /proc/mounts with the longest mount point containing dir.
+func filesystemType(dir string) (string, error) {
+	f, err := os.Open(mountsFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read mounts: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return mountFSType(f, dir)
+}
+
+// mountFSType returns the type of the filesystem holding dir from mounts, a list of mounts in
+// the fstab format of /proc/mounts. Symbolic links in dir are resolved first.
+func mountFSType(mounts io.Reader, dir string) (string, error) {
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	var fstype, mountPoint string
+	scanner := bufio.NewScanner(mounts)
+	for scanner.Scan() {
+		// device mount-point type options dump pass
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		point := unescapeMountPoint(fields[1])
+		if !containsPath(point, path) || len(point) < len(mountPoint) {
+			continue
+		}
+		// Later mounts on the same point hide earlier ones, so they win ties
+		fstype, mountPoint = fields[2], point
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read mounts: %w", err)
+	}
+	if mountPoint == "" {
+		return "", fmt.Errorf("no mount contains %s", path)
+	}
+
+	return fstype, nil
+}
+
+// containsPath reports whether path is mountPoint or inside it.
+func containsPath(mountPoint, path string) bool {
+	if mountPoint == path || mountPoint == "/" {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(mountPoint, "/")+"/")
+}
+
+// unescapeMountPoint decodes the octal escapes /proc/mounts uses for spaces, tabs, newlines,
+// and backslashes in mount points, e.g. \040 for a space.
+func unescapeMountPoint(point string) string {
+	if !strings.Contains(point, `\`) {
+		return point
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(point); i++ {
+		if point[i] == '\\' && i+4 <= len(point) {
+			if c, err := strconv.ParseUint(point[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(point[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+//go:build linux
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountFSType(t *testing.T) {
+	t.Parallel()
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	share := filepath.Join(root, "my share")
+	require.NoError(t, os.MkdirAll(filepath.Join(share, "documents"), 0750))
+	require.NoError(t, os.Symlink(share, filepath.Join(root, "link")))
+
+	mounts := strings.Join([]string{
+		"/dev/sda1 / ext4 rw,relatime 0 0",
+		"tmpfs " + root + " tmpfs rw 0 0",
+		"server:/export " + strings.ReplaceAll(share, " ", `\040`) + " nfs4 rw,vers=4.2 0 0",
+		"proc /proc proc rw 0 0",
+	}, "\n")
+
+	tc := map[string]struct {
+		dir  string
+		want string
+	}{
+		"mount point":                {dir: share, want: "nfs4"},
+		"directory inside mount":     {dir: filepath.Join(share, "documents"), want: "nfs4"},
+		"symlink into mount":         {dir: filepath.Join(root, "link"), want: "nfs4"},
+		"sibling with common prefix": {dir: share + "s", want: "tmpfs"},
+		"parent mount":               {dir: root, want: "tmpfs"},
+		"root mount":                 {dir: "/etc", want: "ext4"},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := mountFSType(strings.NewReader(mounts), tc.dir)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMountFSType_LaterMountWins(t *testing.T) {
+	t.Parallel()
+
+	mounts := "/dev/sda1 / ext4 rw 0 0\nserver:/export / nfs rw 0 0\n"
+
+	got, err := mountFSType(strings.NewReader(mounts), "/etc")
+
+	require.NoError(t, err)
+	assert.Equal(t, "nfs", got)
+}
+
+func TestFilesystemType(t *testing.T) {
+	t.Parallel()
+
+	got, err := filesystemType(t.TempDir())
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, got)
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package config
+
+import "errors"
+
+// filesystemType always fails; filesystem type detection is only supported on Linux and macOS.
+func filesystemType(string) (string, error) {
+	return "", errors.New("filesystem type detection is not supported on this platform")
+}
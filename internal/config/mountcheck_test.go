@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Not parallel: it replaces the default logger.
+func TestCheckNetworkMount(t *testing.T) {
+	tc := map[string]struct {
+		fstype         string
+		fsErr          error
+		requireLocalFS bool
+		wantErr        bool
+		wantWarning    bool
+	}{
+		"local filesystem": {
+			fstype: "ext4",
+		},
+		"local filesystem with local filesystems required": {
+			fstype:         "apfs",
+			requireLocalFS: true,
+		},
+		"network filesystem warns": {
+			fstype:      "nfs4",
+			wantWarning: true,
+		},
+		"network filesystem type is case-insensitive": {
+			fstype:      "SMBFS",
+			wantWarning: true,
+		},
+		"network filesystem with local filesystems required": {
+			fstype:         "cifs",
+			requireLocalFS: true,
+			wantErr:        true,
+		},
+		"undetectable filesystem is assumed local": {
+			fsErr:          errors.New("not supported"),
+			requireLocalFS: true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+			t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+			err := checkNetworkMount("/mnt/share", tc.requireLocalFS, func(dir string) (string, error) {
+				assert.Equal(t, "/mnt/share", dir)
+				return tc.fstype, tc.fsErr
+			})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrNetworkMount)
+				assert.Contains(t, err.Error(), EnvRequireLocalFS)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.wantWarning {
+				assert.Contains(t, logs.String(), `msg="network mount detected" dir=/mnt/share fstype=`+tc.fstype)
+			} else {
+				assert.NotContains(t, logs.String(), "network mount detected")
+			}
+		})
+	}
+}
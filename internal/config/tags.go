@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// fieldErrors are the errors reported when a field breaks a rule of its validate tag, so that
+// callers can tell the fields apart with errors.Is. The aws_region rule reports ErrInvalidAWSRegion,
+// and the s3_bucket rule ErrInvalidS3BucketName.
+var fieldErrors = map[string]error{
+	"BackupDirs":                ErrNoBackupDirs,
+	"CronMissedJob":             ErrInvalidCronMissedJob,
+	"ArchiveMode":               ErrInvalidArchiveMode,
+	"CronWarnLongIntervalHours": ErrInvalidCronWarnInterval,
+	"ScheduleJitterSeconds":     ErrInvalidScheduleJitter,
+	"CronMaxConcurrentRuns":     ErrInvalidCronMaxConcurrentRuns,
+	"JobTimeoutSeconds":         ErrInvalidJobTimeout,
+	"MaxWalkDepth":              ErrInvalidMaxWalkDepth,
+	"RequireMinFiles":           ErrInvalidMinimum,
+	"RequireMinBytes":           ErrInvalidMinimum,
+	"MaxErrors":                 ErrInvalidMaxErrors,
+	"ProgressLogEveryNFiles":    ErrInvalidProgressInterval,
+	"RetentionDays":             ErrInvalidRetentionDays,
+	"MultipartConcurrency":      ErrInvalidMultipartConcurrency,
+	"UploadPartSizeMB":          ErrInvalidPartSize,
+	"Concurrency":               ErrInvalidConcurrency,
+	"ConcurrencyPerDir":         ErrInvalidConcurrency,
+	"MaxObjectKeyLength":        ErrInvalidMaxKeyLength,
+	"PresignExpiryHours":        ErrInvalidPresignExpiry,
+	"CostPerPutUSD":             ErrInvalidCostPerPut,
+	"AWSRegion":                 ErrMissingAWSRegion,
+	"S3Bucket":                  ErrMissingS3BucketName,
+
+	"IntelligentTieringArchiveAccessDays":     ErrInvalidIntelligentTieringDays,
+	"IntelligentTieringDeepArchiveAccessDays": ErrInvalidIntelligentTieringDays,
+}
+
+// validateConfigTags checks the fields of cfg against the comma-separated rules of their
+// validate tag, in field order:
+//   - required: the field must not be empty
+//   - oneof=a b: the string must be one of the space-separated values
+//   - min=n, max=n: the number must not be below or above n
+//   - aws_region: the string must be an AWS region name
+//   - s3_bucket: the string must be a valid S3 bucket name
+//
+// Rules other than required are skipped for empty fields, which select the default.
+// Errors name the environment variable in the env tag of the field.
+//
+// Checks the rules cannot express stay in validateConfig:
+//   - checksum algorithm, Object Lock mode, and storage class map, which are case-insensitive
+//   - cron precision, Object Lock retention, KMS context, and Intelligent-Tiering days, which
+//     depend on other fields
+//   - backup directories and the temp directory, which are checked on the filesystem
+//   - user agent, object key separator, Content-Disposition, CloudWatch namespace, and HTTP proxy,
+//     which are checked for their format
+func validateConfigTags(cfg *Config) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		rules, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+
+		for rule := range strings.SplitSeq(rules, ",") {
+			if err := checkRule(field, v.Field(i), rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRule checks value, the value of field, against a single rule of its validate tag.
+func checkRule(field reflect.StructField, value reflect.Value, rule string) error {
+	name, arg, _ := strings.Cut(rule, "=")
+	env := field.Tag.Get("env")
+	fieldErr := fieldErrors[field.Name]
+
+	if isEmpty(value) {
+		if name == "required" {
+			return fmt.Errorf("%w (set %s or configure in YAML)", fieldErr, env)
+		}
+		return nil
+	}
+
+	switch name {
+	case "required":
+		return nil
+	case "aws_region":
+		return validateAWSRegion(value.String())
+	case "s3_bucket":
+		return validateS3BucketName(value.String())
+	case "oneof":
+		options := strings.Fields(arg)
+		if slices.Contains(options, value.String()) {
+			return nil
+		}
+		return fmt.Errorf("%w: %q (set %s to one of %s, or leave it unset)", fieldErr, value.String(), env,
+			strings.Join(options, ", "))
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("config: invalid bound %q in validate tag of %s: %w", arg, field.Name, err)
+		}
+
+		n := toFloat(value)
+		switch {
+		case name == "min" && n < bound && bound == 0:
+			return fmt.Errorf("%w: %v must not be negative (set %s)", fieldErr, value.Interface(), env)
+		case name == "min" && n < bound:
+			return fmt.Errorf("%w: %v must be at least %s (set %s)", fieldErr, value.Interface(), arg, env)
+		case name == "max" && n > bound:
+			return fmt.Errorf("%w: %v must be at most %s (set %s)", fieldErr, value.Interface(), arg, env)
+		}
+		return nil
+	default:
+		return fmt.Errorf("config: unknown rule %q in validate tag of %s", rule, field.Name)
+	}
+}
+
+// isEmpty reports whether value is the zero value, or an empty slice, map, or string.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// toFloat returns the integer or floating-point number held by value as a float64.
+func toFloat(value reflect.Value) float64 {
+	if value.CanInt() {
+		return float64(value.Int())
+	}
+	return value.Float()
+}
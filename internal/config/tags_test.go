@@ -0,0 +1,208 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfigTags(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		modify  func(cfg *Config)
+		wantErr error
+	}{
+		"valid config": {
+			modify: func(*Config) {},
+		},
+		"missing backup dirs": {
+			modify:  func(cfg *Config) { cfg.BackupDirs = nil },
+			wantErr: ErrNoBackupDirs,
+		},
+		"empty backup dirs": {
+			modify:  func(cfg *Config) { cfg.BackupDirs = []string{} },
+			wantErr: ErrNoBackupDirs,
+		},
+		"missing region": {
+			modify:  func(cfg *Config) { cfg.AWSRegion = "" },
+			wantErr: ErrMissingAWSRegion,
+		},
+		"missing bucket": {
+			modify:  func(cfg *Config) { cfg.S3Bucket = "" },
+			wantErr: ErrMissingS3BucketName,
+		},
+		"invalid region": {
+			modify:  func(cfg *Config) { cfg.AWSRegion = "invalid" },
+			wantErr: ErrInvalidAWSRegion,
+		},
+		"invalid bucket": {
+			modify:  func(cfg *Config) { cfg.S3Bucket = "My_Bucket" },
+			wantErr: ErrInvalidS3BucketName,
+		},
+		"tar.gz archive mode": {
+			modify: func(cfg *Config) { cfg.ArchiveMode = ArchiveModeTarGz },
+		},
+		"unsupported archive mode": {
+			modify:  func(cfg *Config) { cfg.ArchiveMode = "zip" },
+			wantErr: ErrInvalidArchiveMode,
+		},
+		"archive mode in wrong case": {
+			modify:  func(cfg *Config) { cfg.ArchiveMode = "TAR.GZ" },
+			wantErr: ErrInvalidArchiveMode,
+		},
+		"archive mode without compression": {
+			modify:  func(cfg *Config) { cfg.ArchiveMode = "tar" },
+			wantErr: ErrInvalidArchiveMode,
+		},
+		"skip missed jobs": {
+			modify: func(cfg *Config) { cfg.CronMissedJob = CronMissedJobSkip },
+		},
+		"run missed jobs immediately": {
+			modify: func(cfg *Config) { cfg.CronMissedJob = CronMissedJobRunImmediately },
+		},
+		"unsupported missed job policy": {
+			modify:  func(cfg *Config) { cfg.CronMissedJob = "queue" },
+			wantErr: ErrInvalidCronMissedJob,
+		},
+		"missed job policy in wrong case": {
+			modify:  func(cfg *Config) { cfg.CronMissedJob = "SKIP" },
+			wantErr: ErrInvalidCronMissedJob,
+		},
+		"negative minimum": {
+			modify:  func(cfg *Config) { cfg.RequireMinBytes = -1 },
+			wantErr: ErrInvalidMinimum,
+		},
+		"negative cost per PUT": {
+			modify:  func(cfg *Config) { cfg.CostPerPutUSD = -0.5 },
+			wantErr: ErrInvalidCostPerPut,
+		},
+		"multipart concurrency at maximum": {
+			modify: func(cfg *Config) { cfg.MultipartConcurrency = MaxMultipartConcurrency },
+		},
+		"multipart concurrency above maximum": {
+			modify:  func(cfg *Config) { cfg.MultipartConcurrency = MaxMultipartConcurrency + 1 },
+			wantErr: ErrInvalidMultipartConcurrency,
+		},
+		"object key length above maximum": {
+			modify:  func(cfg *Config) { cfg.MaxObjectKeyLength = MaxObjectKeyLength + 1 },
+			wantErr: ErrInvalidMaxKeyLength,
+		},
+		"presign expiry above maximum": {
+			modify:  func(cfg *Config) { cfg.PresignExpiryHours = MaxPresignExpiryHours + 1 },
+			wantErr: ErrInvalidPresignExpiry,
+		},
+		"upload part size at minimum": {
+			modify: func(cfg *Config) { cfg.UploadPartSizeMB = MinUploadPartSizeMB },
+		},
+		"upload part size at maximum": {
+			modify: func(cfg *Config) { cfg.UploadPartSizeMB = MaxUploadPartSizeMB },
+		},
+		"upload part size below minimum": {
+			modify:  func(cfg *Config) { cfg.UploadPartSizeMB = 4 },
+			wantErr: ErrInvalidPartSize,
+		},
+		"upload part size above maximum": {
+			modify:  func(cfg *Config) { cfg.UploadPartSizeMB = MaxUploadPartSizeMB + 1 },
+			wantErr: ErrInvalidPartSize,
+		},
+		"negative upload part size": {
+			modify:  func(cfg *Config) { cfg.UploadPartSizeMB = -5 },
+			wantErr: ErrInvalidPartSize,
+		},
+		"archive access days below minimum": {
+			modify:  func(cfg *Config) { cfg.IntelligentTieringArchiveAccessDays = 30 },
+			wantErr: ErrInvalidIntelligentTieringDays,
+		},
+		"deep archive access days above maximum": {
+			modify:  func(cfg *Config) { cfg.IntelligentTieringDeepArchiveAccessDays = MaxIntelligentTieringAccessDays + 1 },
+			wantErr: ErrInvalidIntelligentTieringDays,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateConfigTags(withTagConfig(tc.modify))
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateConfigTags_ErrorNamesEnvironmentVariable(t *testing.T) {
+	t.Parallel()
+
+	err := validateConfigTags(withTagConfig(func(cfg *Config) { cfg.ConcurrencyPerDir = -2 }))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidConcurrency)
+	assert.Contains(t, err.Error(), "-2 must not be negative")
+	assert.Contains(t, err.Error(), EnvConcurrencyPerDir)
+}
+
+// TestConfigTags checks that every validate tag uses known rules and reports its own error,
+// and that the allowed values and bounds match the constants they duplicate.
+func TestConfigTags(t *testing.T) {
+	t.Parallel()
+
+	typ := reflect.TypeFor[Config]()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		rules, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+
+		assert.Contains(t, fieldErrors, field.Name, "field %s has no error", field.Name)
+		assert.NotEmpty(t, field.Tag.Get("env"), "field %s has no env tag", field.Name)
+		for rule := range strings.SplitSeq(rules, ",") {
+			name, _, _ := strings.Cut(rule, "=")
+			assert.Contains(t, []string{"required", "oneof", "min", "max", "aws_region", "s3_bucket"}, name, "field %s", field.Name)
+		}
+	}
+
+	tags := map[string]string{
+		"CronMissedJob":        "oneof=" + CronMissedJobSkip + " " + CronMissedJobRunImmediately,
+		"ArchiveMode":          "oneof=" + ArchiveModeTarGz,
+		"MultipartConcurrency": "min=0,max=100",
+		"MaxObjectKeyLength":   "min=0,max=1024",
+		"PresignExpiryHours":   "min=0,max=168",
+		"UploadPartSizeMB":     "min=5,max=5120",
+
+		"IntelligentTieringArchiveAccessDays":     "min=90,max=730",
+		"IntelligentTieringDeepArchiveAccessDays": "min=180,max=730",
+	}
+	for name, want := range tags {
+		field, ok := typ.FieldByName(name)
+		require.True(t, ok, name)
+		assert.Equal(t, want, field.Tag.Get("validate"), name)
+	}
+	assert.Equal(t, 100, MaxMultipartConcurrency)
+	assert.Equal(t, 1024, MaxObjectKeyLength)
+	assert.Equal(t, 168, MaxPresignExpiryHours)
+	assert.Equal(t, 5, MinUploadPartSizeMB)
+	assert.Equal(t, 5120, MaxUploadPartSizeMB)
+	assert.Equal(t, 90, DefaultIntelligentTieringArchiveAccessDays)
+	assert.Equal(t, 180, DefaultIntelligentTieringDeepArchiveAccessDays)
+	assert.Equal(t, 730, MaxIntelligentTieringAccessDays)
+}
+
+// withTagConfig returns a config that passes validateConfigTags, changed by modify.
+func withTagConfig(modify func(cfg *Config)) *Config {
+	cfg := &Config{
+		BackupDirs: []string{"/data"},
+		AWSRegion:  "us-east-1",
+		S3Bucket:   "test-bucket",
+	}
+	modify(cfg)
+	return cfg
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// httpClient returns the HTTP client used for AWS requests. It keeps the SDK's default
+// timeouts and only replaces how the proxy of each request is chosen and, if a CA
+// certificate file is configured, which certificate authorities are trusted.
+func (c *Config) httpClient() (*awshttp.BuildableClient, error) {
+	rootCAs, err := c.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+
+	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.Proxy = c.proxyFunc()
+		if rootCAs != nil {
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			}
+			tr.TLSClientConfig.RootCAs = rootCAs
+		}
+	}), nil
+}
+
+// rootCAs returns the system certificate pool extended with the certificates in the configured
+// CA certificate file, so AWS endpoints stay trusted next to a private CA. Returns nil if no
+// file is configured. Returns ErrInvalidCACertFile if the file contains no PEM certificate.
+func (c *Config) rootCAs() (*x509.CertPool, error) {
+	if c.TLSCACertFile == "" {
+		return nil, nil
+	}
+
+	//nolint:gosec // G304: the CA certificate file is chosen by the user
+	data, err := os.ReadFile(c.TLSCACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s (set %s): %w", ErrInvalidCACertFile, c.TLSCACertFile, EnvTLSCACertFile, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w: %s contains no PEM-encoded certificate (set %s)", ErrInvalidCACertFile,
+			c.TLSCACertFile, EnvTLSCACertFile)
+	}
+
+	return pool, nil
+}
+
+// proxyFunc returns the function choosing the proxy of each request. Without a configured
+// HTTP proxy the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables apply. The configured
+// proxy is used directly rather than written to HTTPS_PROXY, since net/http reads the
+// environment only once per process.
+func (c *Config) proxyFunc() func(*http.Request) (*url.URL, error) {
+	if c.HTTPProxy == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	proxyURL, err := url.Parse(c.HTTPProxy)
+	if err != nil {
+		// Rejected by validateConfig; fail every request rather than bypass the proxy
+		return func(*http.Request) (*url.URL, error) { return nil, err }
+	}
+
+	noProxy := parseCommaSeparated(c.NoProxy)
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+}
+
+// bypassProxy reports whether host matches an entry of noProxy. Like NO_PROXY, an entry
+// matches the host itself and its subdomains, a leading dot is ignored, ports are ignored,
+// and "*" matches every host.
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range noProxy {
+		if entry == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(strings.ToLower(entry), ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
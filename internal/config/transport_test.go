@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_GetAWSConfig_HTTPProxy(t *testing.T) {
+	t.Parallel()
+
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests through a proxy carry the absolute target URL
+		proxied <- r.URL.String()
+		_, _ = io.WriteString(w, "proxy")
+	}))
+	t.Cleanup(proxy.Close)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "direct")
+	}))
+	t.Cleanup(target.Close)
+
+	tc := map[string]struct {
+		url         string
+		noProxy     string
+		wantBody    string
+		wantProxied string
+	}{
+		"request routed through proxy": {
+			url:         "http://s3.us-west-2.amazonaws.com/bucket",
+			wantBody:    "proxy",
+			wantProxied: "http://s3.us-west-2.amazonaws.com/bucket",
+		},
+		"host in no proxy list": {
+			url:      target.URL,
+			noProxy:  "minio.internal, 127.0.0.1",
+			wantBody: "direct",
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{AWSRegion: "us-west-2", HTTPProxy: proxy.URL, NoProxy: tc.noProxy}
+			awsCfg, err := cfg.GetAWSConfig(context.Background())
+			require.NoError(t, err)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			resp, err := awsCfg.HTTPClient.Do(req)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = resp.Body.Close() })
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, string(body))
+			if tc.wantProxied != "" {
+				assert.Equal(t, tc.wantProxied, <-proxied)
+			}
+		})
+	}
+}
+
+func TestConfig_GetAWSConfig_TLSCACertFile(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, certPEM, 0600))
+
+	t.Run("handshake succeeds with the CA file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := &Config{AWSRegion: "us-west-2", TLSCACertFile: caFile}
+		awsCfg, err := cfg.GetAWSConfig(context.Background())
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := awsCfg.HTTPClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("handshake fails without the CA file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := &Config{AWSRegion: "us-west-2"}
+		awsCfg, err := cfg.GetAWSConfig(context.Background())
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := awsCfg.HTTPClient.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		require.Error(t, err)
+	})
+
+	for name, content := range map[string][]byte{
+		"not a certificate": []byte("not a certificate"),
+		"empty file":        nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			invalidFile := filepath.Join(t.TempDir(), "ca.pem")
+			require.NoError(t, os.WriteFile(invalidFile, content, 0600))
+
+			_, err := (&Config{AWSRegion: "us-west-2", TLSCACertFile: invalidFile}).GetAWSConfig(context.Background())
+			require.ErrorIs(t, err, ErrInvalidCACertFile)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := (&Config{AWSRegion: "us-west-2", TLSCACertFile: filepath.Join(dir, "missing.pem")}).GetAWSConfig(context.Background())
+		require.ErrorIs(t, err, ErrInvalidCACertFile)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestBypassProxy(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		host    string
+		noProxy []string
+		want    bool
+	}{
+		"empty list":           {host: "s3.amazonaws.com"},
+		"exact host":           {host: "minio.internal", noProxy: []string{"minio.internal"}, want: true},
+		"subdomain":            {host: "s3.minio.internal", noProxy: []string{"minio.internal"}, want: true},
+		"leading dot":          {host: "s3.minio.internal", noProxy: []string{".minio.internal"}, want: true},
+		"entry with port":      {host: "minio.internal", noProxy: []string{"minio.internal:9000"}, want: true},
+		"case insensitive":     {host: "MinIO.Internal", noProxy: []string{"minio.internal"}, want: true},
+		"wildcard":             {host: "s3.amazonaws.com", noProxy: []string{"*"}, want: true},
+		"suffix without dot":   {host: "notminio.internal", noProxy: []string{"minio.internal"}},
+		"unrelated host entry": {host: "s3.amazonaws.com", noProxy: []string{"minio.internal"}},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, bypassProxy(tc.host, tc.noProxy))
+		})
+	}
+}
@@ -1,27 +1,203 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/url"
 	"os"
-	"strconv"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+// awsRegionPattern matches AWS region names such as us-east-1, me-south-1, or us-gov-west-1.
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2,}(-[a-z]+)+-[0-9]+$`)
+
+// s3BucketNamePattern matches S3 bucket names of 3 to 63 lowercase letters, digits, dots, and
+// hyphens that start and end with a letter or digit.
+var s3BucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// ipAddressPattern matches names formatted as an IPv4 address, such as 192.168.5.4.
+var ipAddressPattern = regexp.MustCompile(`^[0-9]{1,3}(\.[0-9]{1,3}){3}$`)
+
+// userAgentPattern matches User-Agent suffixes made of alphanumerics, hyphens, slashes, and dots.
+var userAgentPattern = regexp.MustCompile(`^[A-Za-z0-9./-]*$`)
+
+// cloudWatchNamespacePattern matches the CloudWatch namespaces of custom metrics, made of
+// alphanumerics, spaces, and the characters . - _ / # :
+var cloudWatchNamespacePattern = regexp.MustCompile(`^[A-Za-z0-9 ._/#:-]{1,255}$`)
+
+// constraint is a combination of options that cannot be used together.
+type constraint struct {
+	// name describes the combination in errors
+	name string
+	// check reports whether the configuration violates the constraint
+	check func(cfg *Config) bool
+	// err is the ErrIncompatibleOptions variant returned on violation
+	err error
+}
+
+// constraints are checked by validateConstraints in order. Add new combinations here rather
+// than to validateConfig, so each one is reported with its own ErrIncompatibleOptions variant.
+var constraints = []constraint{
+	{
+		name: "transfer acceleration with path-style addressing",
+		check: func(cfg *Config) bool {
+			return cfg.TransferAcceleration && cfg.UsePathStyle
+		},
+		err: ErrAccelerationWithPathStyle,
+	},
+	{
+		name: "archive mode with incremental backups",
+		check: func(cfg *Config) bool {
+			return cfg.ArchiveMode != ArchiveModeNone && cfg.Incremental
+		},
+		err: ErrArchiveWithIncremental,
+	},
+	{
+		name: "archive mode with file metadata",
+		check: func(cfg *Config) bool {
+			return cfg.ArchiveMode != ArchiveModeNone && cfg.FileMetadata
+		},
+		err: ErrArchiveWithFileMetadata,
+	},
+	{
+		name: "archive mode with skipping unchanged directories",
+		check: func(cfg *Config) bool {
+			return cfg.ArchiveMode != ArchiveModeNone && cfg.SkipUnchangedDirs
+		},
+		err: ErrArchiveWithSkipUnchangedDirs,
+	},
+	{
+		name: "archive mode with empty directory placeholders",
+		check: func(cfg *Config) bool {
+			return cfg.ArchiveMode != ArchiveModeNone && cfg.EmptyDirPlaceholder
+		},
+		err: ErrArchiveWithEmptyDirPlaceholder,
+	},
+	{
+		name: "retention with incremental backups",
+		check: func(cfg *Config) bool {
+			return cfg.RetentionDays > 0 && cfg.Incremental
+		},
+		err: ErrRetentionWithIncremental,
+	},
+	{
+		name: "retention with skipping unchanged directories",
+		check: func(cfg *Config) bool {
+			return cfg.RetentionDays > 0 && cfg.SkipUnchangedDirs
+		},
+		err: ErrRetentionWithSkipUnchangedDirs,
+	},
+	{
+		name: "minimum file count with skipping unchanged directories",
+		check: func(cfg *Config) bool {
+			return cfg.RequireMinFiles > 0 && cfg.SkipUnchangedDirs
+		},
+		err: ErrMinFilesWithSkipUnchangedDirs,
+	},
+	{
+		name: "minimum total size with skipping unchanged directories",
+		check: func(cfg *Config) bool {
+			return cfg.RequireMinBytes > 0 && cfg.SkipUnchangedDirs
+		},
+		err: ErrMinBytesWithSkipUnchangedDirs,
+	},
+	{
+		name: "latest pointer with skipping unchanged directories",
+		check: func(cfg *Config) bool {
+			return cfg.UpdateLatestPointer && cfg.SkipUnchangedDirs
+		},
+		err: ErrLatestPointerWithSkipUnchangedDirs,
+	},
+}
+
+// validateConstraints returns the error of the first violated constraint, or nil if the
+// configuration enables no incompatible options.
+func validateConstraints(cfg *Config) error {
+	for _, c := range constraints {
+		if c.check(cfg) {
+			return fmt.Errorf("%w (%s)", c.err, c.name)
+		}
+	}
+	return nil
+}
+
 // validateConfig validates the entire configuration.
 func validateConfig(cfg *Config) error {
-	if err := validateBackupDirs(cfg.BackupDirs); err != nil {
+	if err := validateConfigTags(cfg); err != nil {
 		return err
 	}
 
-	if err := validateAWSConfig(cfg.AWSRegion, cfg.S3Bucket); err != nil {
+	if err := validateBackupDirs(cfg.BackupDirs, cfg.RequireLocalFS); err != nil {
 		return err
 	}
 
-	return nil
+	if err := validateNestedDirs(cfg.BackupDirs, cfg.AllowNestedDirs); err != nil {
+		return err
+	}
+
+	if err := validateTempDir(cfg.TempDir); err != nil {
+		return err
+	}
+
+	if err := validateCronPrecision(cfg.CronSchedule, cfg.CronPrecision); err != nil {
+		return err
+	}
+
+	if len(cfg.KMSContext) > 0 && cfg.KMSKeyID == "" {
+		return fmt.Errorf("%w (set %s when using %s)", ErrKMSContextWithoutKeyID, EnvKMSKeyID, EnvKMSContext)
+	}
+
+	if err := validateObjectLock(cfg.ObjectLockMode, cfg.ObjectLockRetainDays); err != nil {
+		return err
+	}
+
+	if err := validateUserAgent(cfg.UserAgent); err != nil {
+		return err
+	}
+
+	if err := validateObjectKeySeparator(cfg.ObjectKeySeparator); err != nil {
+		return err
+	}
+
+	if err := validateHTTPProxy(cfg.HTTPProxy); err != nil {
+		return err
+	}
+
+	if err := validateContentDisposition(cfg.ContentDisposition); err != nil {
+		return err
+	}
+
+	if err := validateCloudWatchNamespace(cfg.CloudWatchMetricsNamespace); err != nil {
+		return err
+	}
+
+	if err := validateChecksumAlgorithm(cfg.ChecksumAlgorithm); err != nil {
+		return err
+	}
+
+	if err := validateStorageClassMap(cfg.StorageClassMap); err != nil {
+		return err
+	}
+
+	if err := validateIntelligentTiering(cfg.GetIntelligentTieringArchiveAccessDays(),
+		cfg.GetIntelligentTieringDeepArchiveAccessDays()); err != nil {
+		return err
+	}
+
+	return validateConstraints(cfg)
 }
 
-// validateBackupDirs ensures backup directories are configured and exist.
-func validateBackupDirs(dirs []string) error {
+// validateBackupDirs ensures backup directories are configured and exist, and checks whether
+// they are on a network filesystem.
+func validateBackupDirs(dirs []string, requireLocalFS bool) error {
 	if len(dirs) == 0 {
 		return fmt.Errorf("%w (set %s or configure in YAML)", ErrNoBackupDirs, EnvBackupDirs)
 	}
@@ -30,12 +206,15 @@ func validateBackupDirs(dirs []string) error {
 		if err := validateDirectory(dir); err != nil {
 			return err
 		}
+		if err := checkNetworkMount(dir, requireLocalFS, filesystemType); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// validateDirectory checks if a directory exists and is accessible.
+// validateDirectory checks if a directory exists and can be read by the current user.
 func validateDirectory(dir string) error {
 	fi, err := os.Stat(dir)
 	if err != nil {
@@ -46,52 +225,254 @@ func validateDirectory(dir string) error {
 		return fmt.Errorf("backup directory %s: %w", dir, ErrInvalidDir)
 	}
 
+	// Stat succeeds without read permission, so list a single entry to catch it before the first backup
+	f, err := os.Open(dir) //nolint:gosec // G304: dir is a configured backup directory
+	if err == nil {
+		_, err = f.Readdirnames(1)
+		_ = f.Close()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("backup directory %s: %w: %w", dir, ErrDirectoryNotReadable, err)
+	}
+
 	return nil
 }
 
-// validateAWSConfig ensures AWS region and S3 bucket are configured and valid.
-func validateAWSConfig(region, bucket string) error {
-	if region == "" {
-		return fmt.Errorf("%w (set %s or configure in YAML)", ErrMissingAWSRegion, EnvAWSRegion)
+// validateNestedDirs ensures no backup directory is configured twice or, unless allowNested is
+// set, inside another one, since the files of the inner directory would be uploaded twice.
+// Paths are compared in absolute form.
+func validateNestedDirs(dirs []string, allowNested bool) error {
+	absDirs := make([]string, len(dirs))
+	for i, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("backup directory %s: %w", dir, err)
+		}
+		absDirs[i] = absDir
 	}
 
-	if err := validateAWSRegion(region); err != nil {
-		return err
+	for i, dir := range absDirs {
+		for j := range i {
+			if absDirs[j] == dir {
+				return fmt.Errorf("%w: %s and %s", ErrDuplicateBackupDirs, dirs[j], dirs[i])
+			}
+		}
+	}
+
+	if allowNested {
+		return nil
+	}
+
+	for i, parent := range absDirs {
+		prefix := strings.TrimSuffix(parent, string(filepath.Separator)) + string(filepath.Separator)
+		for j, child := range absDirs {
+			if i != j && strings.HasPrefix(child, prefix) {
+				return fmt.Errorf("%w: %s is inside %s (set %s=true to allow)", ErrNestedBackupDirs,
+					dirs[j], dirs[i], EnvAllowNestedDirs)
+			}
+		}
 	}
 
-	if bucket == "" {
-		return fmt.Errorf("%w (set %s or configure in YAML)", ErrMissingS3BucketName, EnvS3Bucket)
+	return nil
+}
+
+// validateTempDir ensures the temp directory, if set, is a directory temporary files can be
+// created in, by creating and removing one.
+func validateTempDir(dir string) error {
+	if dir == "" {
+		return nil
 	}
 
+	f, err := os.CreateTemp(dir, ".s3-backup-*")
+	if err != nil {
+		return fmt.Errorf("%w: %w (set %s)", ErrTempDirNotWritable, err, EnvTempDir)
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
 	return nil
 }
 
 // validateAWSRegion checks if the AWS region format is valid.
-// AWS regions follow the pattern: {code}-{direction}-{number} (e.g., us-west-2)
+// AWS regions follow the pattern {code}-{direction}-{number} (e.g., us-west-2), optionally
+// with a partition segment such as us-gov-west-1.
 func validateAWSRegion(region string) error {
-	parts := strings.Split(region, "-")
-
-	if len(parts) != 3 {
+	if !awsRegionPattern.MatchString(region) {
 		return fmt.Errorf("%w: expected format {code}-{direction}-{number}", ErrInvalidAWSRegion)
 	}
 
-	// Validate region code (e.g., "us", "eu", "ap")
-	if len(parts[0]) != 2 || parts[0] == "" {
-		return fmt.Errorf("%w: invalid region code", ErrInvalidAWSRegion)
+	return nil
+}
+
+// validateS3BucketName checks the name against the S3 naming rules for general purpose buckets:
+// 3 to 63 lowercase letters, digits, dots, and hyphens, starting and ending with a letter or
+// digit, without adjacent dots, and not formatted as an IP address.
+func validateS3BucketName(name string) error {
+	if !s3BucketNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("%w: %q must be 3 to 63 lowercase letters, digits, dots, and hyphens, starting and ending with a letter or digit (set %s)",
+			ErrInvalidS3BucketName, name, EnvS3Bucket)
+	}
+	if ipAddressPattern.MatchString(name) {
+		return fmt.Errorf("%w: %q must not be formatted as an IP address (set %s)", ErrInvalidS3BucketName, name, EnvS3Bucket)
+	}
+	return nil
+}
+
+// validateChecksumAlgorithm ensures the upload checksum algorithm is one of the supported algorithms.
+// Algorithm names are case-insensitive.
+func validateChecksumAlgorithm(algorithm string) error {
+	switch strings.ToUpper(algorithm) {
+	case ChecksumNone, ChecksumMD5, ChecksumCRC32, ChecksumCRC32C, ChecksumSHA1, ChecksumSHA256:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q (set %s to one of %s, %s, %s, %s, %s or leave it unset)", ErrInvalidChecksumAlgorithm,
+			algorithm, EnvChecksumAlgorithm, ChecksumMD5, ChecksumCRC32, ChecksumCRC32C, ChecksumSHA1, ChecksumSHA256)
+	}
+}
+
+// validateStorageClassMap ensures every entry of the storage class map has an extension and
+// names a storage class S3 knows. Storage class names are case-insensitive.
+func validateStorageClassMap(classes map[string]string) error {
+	known := types.StorageClass("").Values()
+	for _, ext := range slices.Sorted(maps.Keys(classes)) {
+		if normalizeExtension(ext) == "" {
+			return fmt.Errorf("%w: entry for %q must name a file extension (set %s)", ErrInvalidStorageClass, ext, EnvStorageClassMap)
+		}
+		if !slices.Contains(known, types.StorageClass(strings.ToUpper(classes[ext]))) {
+			return fmt.Errorf("%w: %q for extension %q (set %s to classes such as %s, %s, or %s)", ErrInvalidStorageClass,
+				classes[ext], ext, EnvStorageClassMap, types.StorageClassStandard, types.StorageClassStandardIa, types.StorageClassGlacierIr)
+		}
+	}
+	return nil
+}
+
+// validateIntelligentTiering ensures objects move to the Deep Archive Access tier after they
+// move to the Archive Access tier, as S3 requires. The range of each is checked by its validate tag.
+func validateIntelligentTiering(archiveDays, deepArchiveDays int) error {
+	if deepArchiveDays <= archiveDays {
+		return fmt.Errorf("%w: deep archive access after %d days must be later than archive access after %d days (set %s)",
+			ErrInvalidIntelligentTieringDays, deepArchiveDays, archiveDays, EnvIntelligentTieringDeepArchiveAccessDays)
+	}
+	return nil
+}
+
+// validateUserAgent ensures the User-Agent suffix only contains alphanumerics, hyphens, slashes, and dots.
+func validateUserAgent(userAgent string) error {
+	if !userAgentPattern.MatchString(userAgent) {
+		return fmt.Errorf("%w: %q may only contain letters, digits, '-', '/', and '.' (set %s)", ErrInvalidUserAgent, userAgent, EnvUserAgent)
+	}
+	return nil
+}
+
+// validateObjectKeySeparator ensures the object key separator, if set, contains no line breaks,
+// which S3 does not accept in keys, and none of the characters of the timestamp prefix, so that
+// backups can still be listed by their prefix.
+func validateObjectKeySeparator(separator string) error {
+	if strings.ContainsAny(separator, "\r\n") {
+		return fmt.Errorf("%w: %q must not contain line breaks (set %s)", ErrInvalidKeySeparator, separator, EnvObjectKeySeparator)
+	}
+	if strings.ContainsAny(separator, "0123456789-T") {
+		return fmt.Errorf("%w: %q must not contain digits, '-', or 'T', which appear in timestamps (set %s)",
+			ErrInvalidKeySeparator, separator, EnvObjectKeySeparator)
+	}
+	return nil
+}
+
+// validateContentDisposition ensures the Content-Disposition type, if set, is a single token
+// such as attachment or inline, so the filename parameter can be appended to it.
+func validateContentDisposition(disposition string) error {
+	if disposition == "" {
+		return nil
+	}
+
+	if strings.Contains(disposition, "/") || mime.FormatMediaType(disposition, nil) == "" {
+		return fmt.Errorf("%w: %q must be a type such as attachment or inline (set %s)",
+			ErrInvalidContentDisposition, disposition, EnvContentDisposition)
+	}
+	return nil
+}
+
+// validateCloudWatchNamespace ensures the CloudWatch metrics namespace, if set, is one CloudWatch
+// accepts for custom metrics: up to 255 of the characters of cloudWatchNamespacePattern, not all
+// spaces, and not starting with "AWS/", which is reserved for AWS services.
+func validateCloudWatchNamespace(namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+
+	if !cloudWatchNamespacePattern.MatchString(namespace) || strings.TrimSpace(namespace) == "" {
+		return fmt.Errorf("%w: %q may only contain up to 255 letters, digits, spaces, '.', '-', '_', '/', '#', and ':' (set %s)",
+			ErrInvalidCloudWatchNamespace, namespace, EnvCloudWatchMetricsNamespace)
+	}
+	if strings.HasPrefix(namespace, "AWS/") {
+		return fmt.Errorf("%w: %q must not start with AWS/, which is reserved for AWS services (set %s)",
+			ErrInvalidCloudWatchNamespace, namespace, EnvCloudWatchMetricsNamespace)
+	}
+	return nil
+}
+
+// validateHTTPProxy ensures the HTTP proxy, if set, is an absolute URL such as http://proxy:3128.
+func validateHTTPProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%w: %q must be an absolute URL such as http://proxy:3128 (set %s)", ErrInvalidProxyURL, proxy, EnvHTTPProxy)
+	}
+
+	return nil
+}
+
+// validateCronPrecision ensures the cron precision is supported and the cron schedule has the
+// number of fields it requires: five for minute precision and six for second precision.
+// Descriptors such as @daily are accepted with either precision.
+func validateCronPrecision(schedule, precision string) error {
+	if precision == "" {
+		precision = CronPrecisionMinute
+	}
+
+	var want int
+	switch precision {
+	case CronPrecisionMinute:
+		want = 5
+	case CronPrecisionSecond:
+		want = 6
+	default:
+		return fmt.Errorf("%w: %q (set %s to %q or %q)", ErrInvalidCronPrecision, precision, EnvCronPrecision,
+			CronPrecisionMinute, CronPrecisionSecond)
+	}
+
+	if schedule == "" || strings.HasPrefix(schedule, "@") {
+		return nil
+	}
+	if got := len(strings.Fields(schedule)); got != want {
+		return fmt.Errorf("%w: %q has %d fields, %s precision requires %d (set %s)", ErrInvalidCronSchedule,
+			schedule, got, precision, want, EnvCronPrecision)
+	}
+	return nil
+}
+
+// validateObjectLock ensures the Object Lock mode and retention period are either both unset
+// or both valid. Lock modes are case-insensitive.
+func validateObjectLock(mode string, retainDays int) error {
+	if mode == ObjectLockModeNone && retainDays == 0 {
+		return nil
 	}
 
-	// Validate direction (e.g., "east", "west", "central")
-	if parts[1] == "" {
-		return fmt.Errorf("%w: invalid direction", ErrInvalidAWSRegion)
+	if mode == ObjectLockModeNone || retainDays == 0 {
+		return fmt.Errorf("%w (set both %s and %s)", ErrIncompleteLockConfiguration, EnvObjectLockMode, EnvObjectLockRetainDays)
 	}
 
-	// Validate zone number
-	if parts[2] == "" {
-		return fmt.Errorf("%w: invalid zone number", ErrInvalidAWSRegion)
+	switch strings.ToUpper(mode) {
+	case ObjectLockModeGovernance, ObjectLockModeCompliance:
+	default:
+		return fmt.Errorf("%w: %q (set %s to %s or %s)", ErrInvalidObjectLockMode, mode, EnvObjectLockMode,
+			ObjectLockModeGovernance, ObjectLockModeCompliance)
 	}
 
-	if _, err := strconv.Atoi(parts[2]); err != nil {
-		return fmt.Errorf("%w: zone must be a number", ErrInvalidAWSRegion)
+	if retainDays < 0 {
+		return fmt.Errorf("%w: %d must be positive (set %s)", ErrInvalidObjectLockRetainDays, retainDays, EnvObjectLockRetainDays)
 	}
 
 	return nil
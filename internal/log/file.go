@@ -0,0 +1,127 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// RotatingFile is an io.WriteCloser that appends to a log file and rotates it once it would
+// grow beyond a maximum size. Rotated files are renamed to path.1, path.2, and so on, with
+// path.1 the most recent; files beyond the maximum number of backups are deleted.
+// It is safe for concurrent use.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens path for appending, creating it with 0600 permissions if needed.
+// A maxSize of 0 disables rotation; a maxBackups of 0 discards the file on rotation.
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the file, rotating it first if p would not fit. A single write larger
+// than the maximum size is written to a fresh file rather than split.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	const op = "log.RotatingFile.Write"
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// open opens the file at path for appending and records its current size.
+func (f *RotatingFile) open() error {
+	const op = "log.RotatingFile.open"
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("%s: failed to open log file %s: %w", op, f.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("%s: failed to stat log file %s: %w", op, f.path, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups by one, moves the current file to path.1, and opens a new file.
+func (f *RotatingFile) rotate() error {
+	const op = "log.RotatingFile.rotate"
+
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("%s: failed to close log file %s: %w", op, f.path, err)
+	}
+
+	if err := removeIfExists(f.backupPath(f.maxBackups)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		if err := renameIfExists(f.backupPath(i), f.backupPath(i+1)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := renameIfExists(f.path, f.backupPath(1)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return f.open()
+}
+
+// backupPath returns the path of the n-th most recent backup; backup 0 is the current file.
+func (f *RotatingFile) backupPath(n int) string {
+	if n == 0 {
+		return f.path
+	}
+	return f.path + "." + strconv.Itoa(n)
+}
+
+// removeIfExists removes path, ignoring a missing file.
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// renameIfExists renames from to to, ignoring a missing file.
+func renameIfExists(from, to string) error {
+	if err := os.Rename(from, to); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to rename %s to %s: %w", from, to, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "backup.log")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0600))
+
+	f, err := OpenRotatingFile(path, 8, 2)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	// Appended to the existing file until it would exceed 8 bytes
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	assertFileContent(t, path, "four\n")
+	assertFileContent(t, path+".1", "three\n")
+	assertFileContent(t, path+".2", "two\n")
+	assert.NoFileExists(t, path+".3", "backups beyond the maximum must be deleted")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestRotatingFile_NoRotation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "backup.log")
+
+	f, err := OpenRotatingFile(path, 0, 2)
+	require.NoError(t, err)
+	for range 3 {
+		_, err := f.Write([]byte("line\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	assertFileContent(t, path, "line\nline\nline\n")
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestOpenRotatingFile_InvalidPath(t *testing.T) {
+	t.Parallel()
+
+	_, err := OpenRotatingFile(filepath.Join(t.TempDir(), "missing", "backup.log"), 0, 0)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// assertFileContent asserts that the file at path contains want.
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(data))
+}
@@ -0,0 +1,95 @@
+// Package log provides slog handlers for the s3-backup tool, including per-package log levels.
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// LeveledHandler is a slog.Handler that filters records by the level configured for the
+// package that emitted them, falling back to a global level. Packages are identified by
+// import path suffix, e.g. "internal/s3", and resolved from the record's program counter.
+// It replaces the level filtering of the handler it wraps.
+type LeveledHandler struct {
+	next          slog.Handler
+	level         slog.Leveler
+	packageLevels map[string]slog.Level
+}
+
+// NewLeveledHandler returns a LeveledHandler that passes records at or above level, or at or
+// above the level in packageLevels for the emitting package, to next.
+func NewLeveledHandler(next slog.Handler, level slog.Leveler, packageLevels map[string]slog.Level) *LeveledHandler {
+	levels := make(map[string]slog.Level, len(packageLevels))
+	for pkg, pkgLevel := range packageLevels {
+		levels[strings.Trim(pkg, "/")] = pkgLevel
+	}
+
+	return &LeveledHandler{next: next, level: level, packageLevels: levels}
+}
+
+// Enabled reports whether any package could log at level. The emitting package is only
+// known once the record reaches Handle.
+func (h *LeveledHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := h.level.Level()
+	for _, pkgLevel := range h.packageLevels {
+		minLevel = min(minLevel, pkgLevel)
+	}
+	return level >= minLevel
+}
+
+// Handle passes r to the wrapped handler if its level is enabled for the emitting package.
+func (h *LeveledHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levelFor(r.PC) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a LeveledHandler whose wrapped handler has the given attributes.
+func (h *LeveledHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LeveledHandler{next: h.next.WithAttrs(attrs), level: h.level, packageLevels: h.packageLevels}
+}
+
+// WithGroup returns a LeveledHandler whose wrapped handler has the given group.
+func (h *LeveledHandler) WithGroup(name string) slog.Handler {
+	return &LeveledHandler{next: h.next.WithGroup(name), level: h.level, packageLevels: h.packageLevels}
+}
+
+// levelFor returns the minimum level for records emitted at pc.
+func (h *LeveledHandler) levelFor(pc uintptr) slog.Level {
+	if len(h.packageLevels) == 0 || pc == 0 {
+		return h.level.Level()
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := packagePath(frame.Function)
+	for suffix, pkgLevel := range h.packageLevels {
+		if pkg == suffix || strings.HasSuffix(pkg, "/"+suffix) {
+			return pkgLevel
+		}
+	}
+
+	return h.level.Level()
+}
+
+// packagePath returns the import path of the package of a fully qualified function name,
+// e.g. "s3-backup/internal/s3.(*Service).Backup" -> "s3-backup/internal/s3".
+func packagePath(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[lastSlash+1:], "."); dot >= 0 {
+		return function[:lastSlash+1+dot]
+	}
+	return function
+}
+
+// ParseLevel parses a level name such as "DEBUG", "info", or "WARN+2".
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: %w", name, err)
+	}
+	return level, nil
+}
@@ -0,0 +1,93 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"s3-backup/internal/s3"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Not parallel: the s3 package logs through the default logger.
+func TestLeveledHandler_PackageLevel(t *testing.T) {
+	tc := map[string]struct {
+		packageLevels map[string]slog.Level
+		wantDebug     bool
+	}{
+		"s3 debug overrides global info": {
+			packageLevels: map[string]slog.Level{"internal/s3": slog.LevelDebug},
+			wantDebug:     true,
+		},
+		"other package debug": {
+			packageLevels: map[string]slog.Level{"internal/config": slog.LevelDebug},
+			wantDebug:     false,
+		},
+		"global level only": {
+			wantDebug: false,
+		},
+	}
+
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+			slog.SetDefault(slog.New(NewLeveledHandler(handler, slog.LevelInfo, tc.packageLevels)))
+
+			// A backup without directories logs the collected files at DEBUG and the empty backup at WARN
+			require.NoError(t, new(s3.Service).Backup(context.Background()))
+
+			assert.Equal(t, tc.wantDebug, bytes.Contains(buf.Bytes(), []byte("level=DEBUG")))
+			assert.Contains(t, buf.String(), "level=WARN")
+		})
+	}
+}
+
+func TestLeveledHandler_Enabled(t *testing.T) {
+	t.Parallel()
+
+	handler := NewLeveledHandler(slog.DiscardHandler, slog.LevelInfo, nil)
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelInfo))
+
+	handler = NewLeveledHandler(slog.DiscardHandler, slog.LevelError, map[string]slog.Level{"internal/s3": slog.LevelDebug})
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelDebug))
+}
+
+func TestPackagePath(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		function string
+		want     string
+	}{
+		"method":      {function: "s3-backup/internal/s3.(*Service).Backup", want: "s3-backup/internal/s3"},
+		"closure":     {function: "s3-backup/internal/s3.(*Service).Start.func1", want: "s3-backup/internal/s3"},
+		"main":        {function: "main.run", want: "main"},
+		"stdlib":      {function: "log/slog.(*Logger).Info", want: "log/slog"},
+		"dotted host": {function: "example.com/backup/pkg.Run", want: "example.com/backup/pkg"},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, packagePath(tc.function))
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	level, err := ParseLevel("debug")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLevel("verbose")
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// Names of the metrics published by CloudWatchMetricsPublisher.
+const (
+	MetricFilesUploaded         = "FilesUploaded"
+	MetricFilesFailed           = "FilesFailed"
+	MetricBytesUploaded         = "BytesUploaded"
+	MetricBackupDurationSeconds = "BackupDurationSeconds"
+)
+
+// Names of the dimensions of the metrics published by CloudWatchMetricsPublisher.
+const (
+	DimensionHostname = "Hostname"
+	DimensionBucket   = "Bucket"
+)
+
+// ErrEmptyNamespace is returned when a CloudWatchMetricsPublisher is created without a namespace.
+var ErrEmptyNamespace = errors.New("empty CloudWatch namespace")
+
+var _ MetricsPublisher = (*CloudWatchMetricsPublisher)(nil)
+
+// CloudWatchAPI defines the interface for CloudWatch operations needed by CloudWatchMetricsPublisher.
+type CloudWatchAPI interface {
+	PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error)
+}
+
+// CloudWatchMetricsPublisher publishes the metrics of backups to a CloudWatch namespace, with
+// the hostname and the bucket as dimensions.
+type CloudWatchMetricsPublisher struct {
+	client    CloudWatchAPI
+	namespace string
+	hostname  string
+}
+
+// NewCloudWatchMetricsPublisher creates a CloudWatchMetricsPublisher that publishes to namespace
+// with client. The hostname dimension is the name of this host as reported by the kernel.
+func NewCloudWatchMetricsPublisher(client CloudWatchAPI, namespace string) (*CloudWatchMetricsPublisher, error) {
+	const op = "metrics.NewCloudWatchMetricsPublisher"
+
+	if namespace == "" {
+		return nil, fmt.Errorf("%s: %w", op, ErrEmptyNamespace)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get hostname: %w", op, err)
+	}
+
+	return &CloudWatchMetricsPublisher{
+		client:    client,
+		namespace: namespace,
+		hostname:  hostname,
+	}, nil
+}
+
+// Publish puts the metrics of a backup of bucket in a single PutMetricData request.
+func (p *CloudWatchMetricsPublisher) Publish(ctx context.Context, bucket string, m BackupMetrics) error {
+	const op = "metrics.CloudWatchMetricsPublisher.Publish"
+
+	dimensions := []types.Dimension{
+		{Name: aws.String(DimensionHostname), Value: aws.String(p.hostname)},
+		{Name: aws.String(DimensionBucket), Value: aws.String(bucket)},
+	}
+	datum := func(name string, value float64, unit types.StandardUnit) types.MetricDatum {
+		return types.MetricDatum{
+			MetricName: aws.String(name),
+			Dimensions: dimensions,
+			Value:      aws.Float64(value),
+			Unit:       unit,
+		}
+	}
+
+	if _, err := p.client.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+		Namespace: aws.String(p.namespace),
+		MetricData: []types.MetricDatum{
+			datum(MetricFilesUploaded, float64(m.FilesUploaded), types.StandardUnitCount),
+			datum(MetricFilesFailed, float64(m.FilesFailed), types.StandardUnitCount),
+			datum(MetricBytesUploaded, float64(m.BytesUploaded), types.StandardUnitBytes),
+			datum(MetricBackupDurationSeconds, m.Duration.Seconds(), types.StandardUnitSeconds),
+		},
+	}); err != nil {
+		return fmt.Errorf("%s: failed to put metrics to namespace %s: %w", op, p.namespace, err)
+	}
+	return nil
+}
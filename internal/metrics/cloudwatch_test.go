@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errMockCloudWatchFailure = errors.New("mock CloudWatch failure")
+
+// mockCloudWatchClient records the PutMetricData requests it receives.
+type mockCloudWatchClient struct {
+	shouldFail bool
+	inputs     []*cloudwatch.PutMetricDataInput
+}
+
+func (m *mockCloudWatchClient) PutMetricData(_ context.Context, params *cloudwatch.PutMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	m.inputs = append(m.inputs, params)
+	if m.shouldFail {
+		return nil, errMockCloudWatchFailure
+	}
+	return &cloudwatch.PutMetricDataOutput{}, nil
+}
+
+func TestNewCloudWatchMetricsPublisher(t *testing.T) {
+	t.Parallel()
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	tc := map[string]struct {
+		namespace string
+		wantErr   error
+	}{
+		"with namespace":  {namespace: "S3Backup/Prod"},
+		"empty namespace": {wantErr: ErrEmptyNamespace},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			publisher, err := NewCloudWatchMetricsPublisher(&mockCloudWatchClient{}, tc.namespace)
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.namespace, publisher.namespace)
+			assert.Equal(t, hostname, publisher.hostname)
+		})
+	}
+}
+
+func TestCloudWatchMetricsPublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	dimensions := []types.Dimension{
+		{Name: aws.String(DimensionHostname), Value: aws.String("backup-host")},
+		{Name: aws.String(DimensionBucket), Value: aws.String("test-bucket")},
+	}
+	datum := func(name string, value float64, unit types.StandardUnit) types.MetricDatum {
+		return types.MetricDatum{MetricName: aws.String(name), Dimensions: dimensions, Value: aws.Float64(value), Unit: unit}
+	}
+
+	tc := map[string]struct {
+		shouldFail bool
+		wantErr    bool
+	}{
+		"success":         {},
+		"request failure": {shouldFail: true, wantErr: true},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockCloudWatchClient{shouldFail: tc.shouldFail}
+			publisher := &CloudWatchMetricsPublisher{client: client, namespace: "S3Backup/Prod", hostname: "backup-host"}
+
+			err := publisher.Publish(context.Background(), "test-bucket", BackupMetrics{
+				FilesUploaded: 3,
+				FilesFailed:   1,
+				BytesUploaded: 2048,
+				Duration:      1500 * time.Millisecond,
+			})
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errMockCloudWatchFailure)
+				assert.Contains(t, err.Error(), "S3Backup/Prod")
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, client.inputs, 1)
+			assert.Equal(t, "S3Backup/Prod", aws.ToString(client.inputs[0].Namespace))
+			assert.Equal(t, []types.MetricDatum{
+				datum(MetricFilesUploaded, 3, types.StandardUnitCount),
+				datum(MetricFilesFailed, 1, types.StandardUnitCount),
+				datum(MetricBytesUploaded, 2048, types.StandardUnitBytes),
+				datum(MetricBackupDurationSeconds, 1.5, types.StandardUnitSeconds),
+			}, client.inputs[0].MetricData)
+		})
+	}
+}
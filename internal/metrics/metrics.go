@@ -0,0 +1,21 @@
+// Package metrics publishes the results of backups to monitoring systems.
+package metrics
+
+import (
+	"context"
+	"time"
+)
+
+// BackupMetrics are the metrics of a single backup.
+type BackupMetrics struct {
+	FilesUploaded int
+	FilesFailed   int
+	BytesUploaded int64
+	Duration      time.Duration
+}
+
+// MetricsPublisher publishes the metrics of backups to a monitoring system.
+type MetricsPublisher interface {
+	// Publish publishes the metrics of a backup of bucket.
+	Publish(ctx context.Context, bucket string, m BackupMetrics) error
+}
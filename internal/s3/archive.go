@@ -0,0 +1,180 @@
+package s3
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// archiveExtension is appended to the base directory name to form the archive object name.
+const archiveExtension = ".tar.gz"
+
+// backupArchives packs the files collected from each backup directory into a tar.gz archive
+// and uploads it. It continues with the remaining directories if one fails, collecting all errors.
+func (s *Service) backupArchives(ctx context.Context, target backupTarget, collectors []*fileCollector, timestamp time.Time) error {
+	const op = "s3.Service.backupArchives"
+
+	var joinedErrs error
+	for _, collector := range collectors {
+		// Check for context cancellation
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		default:
+		}
+
+		if err := s.backupArchive(ctx, target, collector.dir, collector.files, timestamp); err != nil {
+			target.stats.recordFailure()
+			joinedErrs = errors.Join(joinedErrs, err)
+		}
+	}
+
+	if joinedErrs != nil {
+		return fmt.Errorf("%s: one or more directories failed to backup: %w", op, joinedErrs)
+	}
+	return nil
+}
+
+// backupArchive archives the files collected from a single directory into a temporary file and
+// uploads it. dir must be absolute, as collected by scanDir, so "." is archived under the working
+// directory's name. The temporary file is always removed, even if archiving or uploading fails.
+// The S3 object key is the timestamp prefix followed by the directory name, e.g. 2025-12-15T14-30-00/documents.tar.gz.
+func (s *Service) backupArchive(ctx context.Context, target backupTarget, dir string, files []string, timestamp time.Time) error {
+	const op = "s3.Service.backupArchive"
+
+	tmpFile, err := os.CreateTemp(s.tempDir, "s3-backup-*"+archiveExtension)
+	if err != nil {
+		return fmt.Errorf("%s: failed to create temp file: %w", op, err)
+	}
+	tmpPath := tmpFile.Name()
+	defer func() {
+		if removeErr := os.Remove(tmpPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			s.logger().Warn("failed to remove temp archive", "file", tmpPath, "error", removeErr)
+		}
+	}()
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("%s: failed to close temp file %s: %w", op, tmpPath, err)
+	}
+
+	if err := s.archiveDirectory(ctx, dir, files, tmpPath); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	//nolint:gosec // G304: tmpPath is a temp file created above
+	archive, err := os.Open(tmpPath)
+	if err != nil {
+		return fmt.Errorf("%s: failed to open archive %s: %w", op, tmpPath, err)
+	}
+	defer func() {
+		if closeErr := archive.Close(); closeErr != nil {
+			s.logger().Warn("failed to close file", "file", tmpPath, "error", closeErr)
+		}
+	}()
+
+	key := archiveKey(dir, s.keySeparator(), s.prefixLayout(), timestamp)
+	if err := s.putFile(ctx, archive, key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	target.stats.recordUpload(key, fileSize(archive))
+	return nil
+}
+
+// archiveKey returns the object key of the archive of dir, which must be an absolute path.
+func archiveKey(dir, sep, layout string, timestamp time.Time) string {
+	return buildObjectKey(filepath.Base(dir)+archiveExtension, sep, layout, timestamp)
+}
+
+// archiveDirectory writes a gzip-compressed tar archive of files, collected from dir, to destFile.
+// Entries are named with the base directory name as prefix, e.g. documents/invoices/invoice-001.txt.
+func (s *Service) archiveDirectory(ctx context.Context, dir string, files []string, destFile string) (err error) {
+	const op = "s3.Service.archiveDirectory"
+
+	//nolint:gosec // G304: destFile is a temp file created by the service
+	out, err := os.Create(destFile)
+	if err != nil {
+		return fmt.Errorf("%s: failed to create archive %s: %w", op, destFile, err)
+	}
+	defer func() {
+		if closeErr := out.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("%s: failed to close archive %s: %w", op, destFile, closeErr))
+		}
+	}()
+
+	gzw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gzw)
+
+	baseDir := filepath.Base(dir)
+	for _, file := range files {
+		// Check for context cancellation
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		default:
+		}
+
+		relPath, err := filepath.Rel(dir, file)
+		if err != nil {
+			return fmt.Errorf("%s: failed to resolve path %s: %w", op, file, err)
+		}
+
+		if err := addFileToArchive(s.logger(), tw, file, filepath.ToSlash(filepath.Join(baseDir, relPath))); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("%s: failed to finalize tar archive: %w", op, err)
+	}
+	if err := gzw.Close(); err != nil {
+		return fmt.Errorf("%s: failed to finalize gzip stream: %w", op, err)
+	}
+
+	return nil
+}
+
+// addFileToArchive writes a single file to the tar archive under the given entry name.
+func addFileToArchive(logger *slog.Logger, tw *tar.Writer, fileName, name string) error {
+	const op = "s3.addFileToArchive"
+
+	//nolint:gosec // G304: fileName comes from user's configured backup directories
+	file, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("%s: failed to open file %s: %w", op, fileName, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			logger.Warn("failed to close file", "file", fileName, "error", closeErr)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("%s: failed to stat file %s: %w", op, fileName, err)
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return fmt.Errorf("%s: failed to create tar header for %s: %w", op, fileName, err)
+	}
+	header.Name = name
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("%s: failed to write tar header for %s: %w", op, fileName, err)
+	}
+
+	// Copy exactly the size recorded in the header so a file growing mid-backup cannot corrupt the archive
+	if _, err := io.CopyN(tw, file, header.Size); err != nil {
+		return fmt.Errorf("%s: failed to write %s to archive: %w", op, fileName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package s3
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"s3-backup/internal/config"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ArchiveDirectory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		recursive bool
+		want      map[string]string
+	}{
+		"non-recursive archive contains only top-level files": {
+			recursive: false,
+			want: map[string]string{
+				"file1.txt": "content1",
+				"file2.txt": "content2",
+			},
+		},
+		"recursive archive contains nested files": {
+			recursive: true,
+			want: map[string]string{
+				"file1.txt":            "content1",
+				"file2.txt":            "content2",
+				"subdir/nested.txt":    "nested",
+				"subdir/deep/deep.txt": "deep",
+			},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "file1.txt", "content1")
+			createFile(t, dir, "file2.txt", "content2")
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "subdir", "deep"), 0750))
+			createFile(t, filepath.Join(dir, "subdir"), "nested.txt", "nested")
+			createFile(t, filepath.Join(dir, "subdir", "deep"), "deep.txt", "deep")
+
+			svc := &Service{backupDirs: []string{dir}, recursive: tc.recursive}
+			dest := filepath.Join(t.TempDir(), "archive.tar.gz")
+
+			files, _, err := svc.collectFilesFromDir(ctx, dir, tc.recursive)
+			require.NoError(t, err)
+			require.NoError(t, svc.archiveDirectory(ctx, dir, files, dest))
+
+			want := make(map[string]string, len(tc.want))
+			for name, content := range tc.want {
+				want[filepath.Base(dir)+"/"+name] = content
+			}
+			assert.Equal(t, want, readArchive(t, dest))
+		})
+	}
+}
+
+func TestService_ArchiveDirectory_ContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file1.txt", "content1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Cancel immediately
+
+	svc := &Service{backupDirs: []string{dir}}
+	files := []string{filepath.Join(dir, "file1.txt")}
+	err := svc.archiveDirectory(ctx, dir, files, filepath.Join(t.TempDir(), "archive.tar.gz"))
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestService_Backup_ArchiveMode(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		client  *mockS3Client
+		wantErr error
+	}{
+		"uploads one archive per directory": {
+			client: &mockS3Client{},
+		},
+		"upload failure still removes temp archives": {
+			client:  &mockS3Client{shouldFail: true},
+			wantErr: errMockS3Failure,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpDir := t.TempDir()
+			dirs := createTempDirs(t, 2)
+			for _, dir := range dirs {
+				createFile(t, dir, "file.txt", "content")
+			}
+
+			svc := &Service{
+				client:      tc.client,
+				bucketName:  "test-bucket",
+				backupDirs:  dirs,
+				archiveMode: config.ArchiveModeTarGz,
+				tempDir:     tmpDir,
+			}
+
+			err := svc.Backup(context.Background())
+
+			entries, readErr := os.ReadDir(tmpDir)
+			require.NoError(t, readErr)
+			assert.Empty(t, entries, "temp archives should be removed")
+
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, tc.client.putKeys, len(dirs))
+
+			timestampPattern := `^\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}/`
+			var names []string
+			for _, key := range tc.client.putKeys {
+				assert.Regexp(t, timestampPattern, key)
+				names = append(names, filepath.Base(key))
+			}
+			sort.Strings(names)
+
+			var want []string
+			for _, dir := range dirs {
+				want = append(want, filepath.Base(dir)+archiveExtension)
+			}
+			sort.Strings(want)
+			assert.Equal(t, want, names)
+		})
+	}
+}
+
+func TestService_Backup_ArchiveWalksEachDirOnce(t *testing.T) {
+	t.Parallel()
+
+	dirs := createTempDirs(t, 2)
+	for _, dir := range dirs {
+		createFile(t, dir, "file.txt", "content")
+	}
+
+	logs := &logRecorder{}
+	svc := &Service{
+		client:      &mockS3Client{},
+		bucketName:  "test-bucket",
+		backupDirs:  dirs,
+		archiveMode: config.ArchiveModeTarGz,
+		tempDir:     t.TempDir(),
+		log:         slog.New(logs),
+	}
+
+	require.NoError(t, svc.Backup(context.Background()))
+	assert.Len(t, logs.findAll("directory scan complete"), len(dirs))
+}
+
+func TestService_Backup_ArchiveInTempDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+	tmpDir := t.TempDir()
+
+	client := &tempDirS3Client{tempDir: tmpDir}
+	svc := &Service{
+		client:      client,
+		bucketName:  "test-bucket",
+		backupDirs:  []string{dir},
+		archiveMode: config.ArchiveModeTarGz,
+		tempDir:     tmpDir,
+	}
+
+	require.NoError(t, svc.Backup(context.Background()))
+	require.Len(t, client.uploading, 1)
+	assert.Regexp(t, `^s3-backup-.*`+regexp.QuoteMeta(archiveExtension)+`$`, client.uploading[0])
+}
+
+// tempDirS3Client records the files in tempDir while each object is uploaded.
+type tempDirS3Client struct {
+	mockS3Client
+
+	tempDir   string
+	uploading []string
+}
+
+func (c *tempDirS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	entries, err := os.ReadDir(c.tempDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		c.uploading = append(c.uploading, entry.Name())
+	}
+	return c.mockS3Client.PutObject(ctx, params, optFns...)
+}
+
+func TestBackupArchive_ObjectKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+
+	client := &mockS3Client{}
+	svc := &Service{client: client, bucketName: "test-bucket", backupDirs: []string{dir}}
+	ts := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+
+	files := []string{filepath.Join(dir, "file.txt")}
+	require.NoError(t, svc.backupArchive(context.Background(), svc.snapshotTarget(), dir, files, ts))
+
+	require.Len(t, client.putKeys, 1)
+	assert.Equal(t, "2025-12-15T14-30-00/"+filepath.Base(dir)+".tar.gz", client.putKeys[0])
+
+	// The archive is written to a temp file first, so its compressed size is known up front
+	require.NotNil(t, client.putInputs[0].ContentLength)
+	assert.Equal(t, client.putSizes[0], *client.putInputs[0].ContentLength)
+}
+
+// readArchive decompresses a tar.gz file and returns its entries mapped to their contents.
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+
+	//nolint:gosec // G304: path is a test temp file
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	gzr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	entries := make(map[string]string)
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		entries[header.Name] = string(content)
+	}
+
+	return entries
+}
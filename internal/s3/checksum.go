@@ -0,0 +1,94 @@
+package s3
+
+import (
+	"crypto/md5"  //nolint:gosec // G501: Content-MD5 is an integrity check required by the S3 API
+	"crypto/sha1" //nolint:gosec // G505: SHA1 is an integrity checksum supported by S3, it is not used for security
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"s3-backup/internal/config"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// newChecksumHash returns a hash for the given checksum algorithm,
+// or nil if the algorithm is config.ChecksumNone or unsupported.
+func newChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case config.ChecksumMD5:
+		return md5.New() //nolint:gosec // G401: Content-MD5 is an integrity check required by the S3 API
+	case config.ChecksumCRC32:
+		return crc32.NewIEEE()
+	case config.ChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case config.ChecksumSHA1:
+		return sha1.New() //nolint:gosec // G401: SHA1 is an integrity checksum supported by S3, it is not used for security
+	case config.ChecksumSHA256:
+		return sha256.New()
+	default:
+		return nil
+	}
+}
+
+// fileChecksum returns the base64-encoded checksum of the file's contents and rewinds the file
+// so it can be uploaded. The checksum must be known before the request is sent, because S3
+// verifies it against the header.
+func fileChecksum(file *os.File, algorithm string) (string, error) {
+	const op = "s3.fileChecksum"
+
+	h := newChecksumHash(algorithm)
+	if h == nil {
+		return "", fmt.Errorf("%s: unsupported checksum algorithm %q", op, algorithm)
+	}
+
+	if _, err := io.Copy(io.Discard, io.TeeReader(file, h)); err != nil {
+		return "", fmt.Errorf("%s: failed to read file %s: %w", op, file.Name(), err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("%s: failed to rewind file %s: %w", op, file.Name(), err)
+	}
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksumAlgorithmType returns the S3 checksum algorithm for the given algorithm name.
+// It returns an empty algorithm for MD5, which S3 verifies through Content-MD5 instead.
+func checksumAlgorithmType(algorithm string) types.ChecksumAlgorithm {
+	switch algorithm {
+	case config.ChecksumCRC32:
+		return types.ChecksumAlgorithmCrc32
+	case config.ChecksumCRC32C:
+		return types.ChecksumAlgorithmCrc32c
+	case config.ChecksumSHA1:
+		return types.ChecksumAlgorithmSha1
+	case config.ChecksumSHA256:
+		return types.ChecksumAlgorithmSha256
+	default:
+		return ""
+	}
+}
+
+// applyChecksum sets the checksum fields of input for the given algorithm.
+// MD5 uses the legacy Content-MD5 header; the other algorithms use the
+// x-amz-checksum-* headers together with ChecksumAlgorithm.
+func applyChecksum(input *s3.PutObjectInput, algorithm, checksum string) {
+	input.ChecksumAlgorithm = checksumAlgorithmType(algorithm)
+
+	switch algorithm {
+	case config.ChecksumMD5:
+		input.ContentMD5 = &checksum
+	case config.ChecksumCRC32:
+		input.ChecksumCRC32 = &checksum
+	case config.ChecksumCRC32C:
+		input.ChecksumCRC32C = &checksum
+	case config.ChecksumSHA1:
+		input.ChecksumSHA1 = &checksum
+	case config.ChecksumSHA256:
+		input.ChecksumSHA256 = &checksum
+	}
+}
@@ -0,0 +1,112 @@
+package s3
+
+import (
+	"context"
+	"path/filepath"
+	"s3-backup/internal/config"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BackupFile_Checksum(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		algorithm     string
+		wantAlgorithm types.ChecksumAlgorithm
+		wantChecksum  func(input *s3.PutObjectInput) *string
+		want          string
+	}{
+		"md5 uses Content-MD5": {
+			algorithm:    config.ChecksumMD5,
+			wantChecksum: func(input *s3.PutObjectInput) *string { return input.ContentMD5 },
+			want:         "XrY7u+Ae7tCTyyK7j1rNww==",
+		},
+		"crc32": {
+			algorithm:     config.ChecksumCRC32,
+			wantAlgorithm: types.ChecksumAlgorithmCrc32,
+			wantChecksum:  func(input *s3.PutObjectInput) *string { return input.ChecksumCRC32 },
+			want:          "DUoRhQ==",
+		},
+		"crc32c": {
+			algorithm:     config.ChecksumCRC32C,
+			wantAlgorithm: types.ChecksumAlgorithmCrc32c,
+			wantChecksum:  func(input *s3.PutObjectInput) *string { return input.ChecksumCRC32C },
+			want:          "yZRlqg==",
+		},
+		"sha1": {
+			algorithm:     config.ChecksumSHA1,
+			wantAlgorithm: types.ChecksumAlgorithmSha1,
+			wantChecksum:  func(input *s3.PutObjectInput) *string { return input.ChecksumSHA1 },
+			want:          "Kq5sNclPz7QV2+lfQIuc6R7oRu0=",
+		},
+		"sha256": {
+			algorithm:     config.ChecksumSHA256,
+			wantAlgorithm: types.ChecksumAlgorithmSha256,
+			wantChecksum:  func(input *s3.PutObjectInput) *string { return input.ChecksumSHA256 },
+			want:          "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "hello.txt", "hello world")
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:            client,
+				bucketName:        "test-bucket",
+				backupDirs:        []string{dir},
+				checksumAlgorithm: tc.algorithm,
+			}
+
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filepath.Join(dir, "hello.txt"), time.Now()))
+			require.Len(t, client.putInputs, 1)
+
+			input := client.putInputs[0]
+			assert.Equal(t, tc.wantAlgorithm, input.ChecksumAlgorithm)
+			require.NotNil(t, tc.wantChecksum(input))
+			assert.Equal(t, tc.want, *tc.wantChecksum(input))
+		})
+	}
+}
+
+func TestService_BackupFile_NoChecksum(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "hello.txt", "hello world")
+
+	client := &mockS3Client{}
+	svc := &Service{
+		client:     client,
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+	}
+
+	require.NoError(t, svc.backupFile(context.Background(), svc.snapshotTarget(), filepath.Join(dir, "hello.txt"), time.Now()))
+	require.Len(t, client.putInputs, 1)
+
+	input := client.putInputs[0]
+	assert.Empty(t, input.ChecksumAlgorithm)
+	assert.Nil(t, input.ContentMD5)
+	assert.Nil(t, input.ChecksumSHA256)
+}
+
+func TestChecksumAlgorithmType(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, types.ChecksumAlgorithmSha256, checksumAlgorithmType(config.ChecksumSHA256))
+	assert.Empty(t, checksumAlgorithmType(config.ChecksumMD5))
+	assert.Empty(t, checksumAlgorithmType(config.ChecksumNone))
+}
@@ -0,0 +1,87 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"s3-backup/internal/config"
+	"time"
+)
+
+// PlannedObject is an object a backup would upload.
+type PlannedObject struct {
+	LocalPath string `json:"local_path"`
+	S3Key     string `json:"s3_key"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// PlanBackup returns the objects a backup started now would upload, without uploading anything.
+// In archive mode one object is planned per backup directory, sized by the uncompressed files
+// it contains. Incremental mode is ignored, so unchanged files are planned as well.
+func (s *Service) PlanBackup(ctx context.Context) ([]PlannedObject, error) {
+	const op = "s3.Service.PlanBackup"
+
+	target := s.snapshotTarget()
+	timestamp := s.now()
+
+	if s.archiveMode == config.ArchiveModeTarGz {
+		return s.planArchives(ctx, target, timestamp)
+	}
+
+	files, _, err := s.collectAllFiles(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to collect files: %w", op, err)
+	}
+
+	planned := make([]PlannedObject, 0, len(files))
+	for _, file := range files {
+		s3Key, err := s.buildS3Key(target, file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		size, err := totalSize(ctx, []string{file})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		planned = append(planned, PlannedObject{
+			LocalPath: file,
+			S3Key:     buildObjectKey(s3Key, s.keySeparator(), s.prefixLayout(), timestamp),
+			SizeBytes: size,
+		})
+	}
+
+	return planned, nil
+}
+
+// planArchives returns one planned archive per backup directory of target.
+func (s *Service) planArchives(ctx context.Context, target backupTarget, timestamp time.Time) ([]PlannedObject, error) {
+	const op = "s3.Service.planArchives"
+
+	planned := make([]PlannedObject, 0, len(target.dirs))
+	for _, dir := range target.dirs {
+		files, _, err := s.collectFilesFromDir(ctx, dir, target.recursive)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		size, err := totalSize(ctx, files)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to resolve absolute path of %s: %w", op, dir, err)
+		}
+
+		planned = append(planned, PlannedObject{
+			LocalPath: absDir,
+			S3Key:     archiveKey(absDir, s.keySeparator(), s.prefixLayout(), timestamp),
+			SizeBytes: size,
+		})
+	}
+
+	return planned, nil
+}
@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"s3-backup/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PlanBackup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+
+	dir := filepath.Join(t.TempDir(), "documents")
+	subdir := filepath.Join(dir, "invoices")
+	require.NoError(t, os.MkdirAll(subdir, 0750))
+	createFile(t, dir, "a.txt", "1234")
+	createFile(t, subdir, "b.txt", "nested")
+
+	tc := map[string]struct {
+		archiveMode string
+		want        []PlannedObject
+	}{
+		"individual files": {
+			want: []PlannedObject{
+				{LocalPath: filepath.Join(dir, "a.txt"), S3Key: "2025-12-15T14-30-00/documents/a.txt", SizeBytes: 4},
+				{LocalPath: filepath.Join(subdir, "b.txt"), S3Key: "2025-12-15T14-30-00/documents/invoices/b.txt", SizeBytes: 6},
+			},
+		},
+		"archive mode plans one object per directory": {
+			archiveMode: config.ArchiveModeTarGz,
+			want: []PlannedObject{
+				{LocalPath: dir, S3Key: "2025-12-15T14-30-00/documents.tar.gz", SizeBytes: 10},
+			},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:      client,
+				bucketName:  "test-bucket",
+				backupDirs:  []string{dir},
+				recursive:   true,
+				archiveMode: tc.archiveMode,
+				nowFunc:     func() time.Time { return now },
+			}
+
+			got, err := svc.PlanBackup(ctx)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.want, got)
+			assert.Empty(t, client.putKeys, "a dry run must not upload anything")
+		})
+	}
+}
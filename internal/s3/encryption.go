@@ -0,0 +1,34 @@
+package s3
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// encodeKMSContext encodes an SSE-KMS encryption context the way S3 expects it:
+// a base64-encoded JSON object. Returns empty string if the context is empty.
+func encodeKMSContext(kmsContext map[string]string) string {
+	if len(kmsContext) == 0 {
+		return ""
+	}
+
+	// Marshalling a map of strings cannot fail
+	encoded, _ := json.Marshal(kmsContext)
+	return base64.StdEncoding.EncodeToString(encoded)
+}
+
+// applyEncryption sets the SSE-KMS fields of input when a KMS key is configured.
+func (s *Service) applyEncryption(input *s3.PutObjectInput) {
+	if s.kmsKeyID == "" {
+		return
+	}
+
+	input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+	input.SSEKMSKeyId = &s.kmsKeyID
+	if s.kmsContext != "" {
+		input.SSEKMSEncryptionContext = &s.kmsContext
+	}
+}
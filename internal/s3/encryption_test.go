@@ -0,0 +1,77 @@
+package s3
+
+import (
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BackupFile_KMSEncryption(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		kmsKeyID    string
+		kmsContext  map[string]string
+		wantSSE     types.ServerSideEncryption
+		wantContext string
+	}{
+		"no KMS key uses bucket default encryption": {},
+		"KMS key without context": {
+			kmsKeyID: "alias/backups",
+			wantSSE:  types.ServerSideEncryptionAwsKms,
+		},
+		"KMS key with context": {
+			kmsKeyID:    "alias/backups",
+			kmsContext:  map[string]string{"team": "platform", "app": "s3-backup"},
+			wantSSE:     types.ServerSideEncryptionAwsKms,
+			wantContext: `{"app":"s3-backup","team":"platform"}`,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "secret.txt", "content")
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:     client,
+				bucketName: "test-bucket",
+				backupDirs: []string{dir},
+				kmsKeyID:   tc.kmsKeyID,
+				kmsContext: encodeKMSContext(tc.kmsContext),
+			}
+
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filepath.Join(dir, "secret.txt"), time.Now()))
+			require.Len(t, client.putInputs, 1)
+
+			input := client.putInputs[0]
+			assert.Equal(t, tc.wantSSE, input.ServerSideEncryption)
+			if tc.kmsKeyID == "" {
+				assert.Nil(t, input.SSEKMSKeyId)
+				return
+			}
+			require.NotNil(t, input.SSEKMSKeyId)
+			assert.Equal(t, tc.kmsKeyID, *input.SSEKMSKeyId)
+
+			if tc.wantContext == "" {
+				assert.Nil(t, input.SSEKMSEncryptionContext)
+				return
+			}
+			require.NotNil(t, input.SSEKMSEncryptionContext)
+			decoded, err := base64.StdEncoding.DecodeString(*input.SSEKMSEncryptionContext)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.wantContext, string(decoded))
+		})
+	}
+}
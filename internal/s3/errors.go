@@ -1,7 +1,10 @@
 // Package s3 provides S3 backup functionality including file collection and upload.
 package s3
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrNilConfig indicates that a nil config was provided.
@@ -18,4 +21,50 @@ var (
 
 	// ErrNotADirectory indicates that a path is not a directory.
 	ErrNotADirectory = errors.New("path is not a directory")
+
+	// ErrDirectoryNotConfigured indicates that a directory is not one of the configured backup directories.
+	ErrDirectoryNotConfigured = errors.New("directory is not a configured backup directory")
+
+	// ErrDirectoryNotReadable indicates that a directory exists but cannot be read by the current user.
+	ErrDirectoryNotReadable = errors.New("directory is not readable")
+
+	// ErrTooFewFiles indicates that a backup found fewer files than the configured minimum.
+	ErrTooFewFiles = errors.New("too few files to backup")
+
+	// ErrTooFewBytes indicates that a backup found less data than the configured minimum.
+	ErrTooFewBytes = errors.New("too few bytes to backup")
+
+	// ErrVersioningNotEnabled indicates that the bucket does not have versioning enabled.
+	ErrVersioningNotEnabled = errors.New("bucket versioning is not enabled")
+
+	// ErrBucketNotAccessible indicates that the preflight check could not access the bucket.
+	ErrBucketNotAccessible = errors.New("bucket is not accessible")
+
+	// ErrMaxErrorsExceeded indicates that a backup was aborted after too many consecutive upload failures.
+	ErrMaxErrorsExceeded = errors.New("too many consecutive upload failures")
+
+	// ErrObjectKeyTooLong indicates that the S3 key of a file exceeds the maximum object key length.
+	ErrObjectKeyTooLong = errors.New("object key too long")
+
+	// ErrSchedulerStopped indicates that the scheduler was stopped before a scheduled backup started.
+	ErrSchedulerStopped = errors.New("scheduler stopped")
 )
+
+// BackupError is returned for a file that could not be backed up. Backups join one per
+// failed file, so errors.As recovers the path without parsing the message.
+type BackupError struct {
+	// FilePath is the path of the file that failed, as collected from the backup directory.
+	FilePath string
+	// Cause is the underlying error.
+	Cause error
+}
+
+// Error returns the underlying error message followed by the file path.
+func (e *BackupError) Error() string {
+	return fmt.Sprintf("%v (file=%s)", e.Cause, e.FilePath)
+}
+
+// Unwrap returns the underlying error, so errors.Is matches sentinel errors such as ErrEmptyFilename.
+func (e *BackupError) Unwrap() error {
+	return e.Cause
+}
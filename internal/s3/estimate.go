@@ -0,0 +1,43 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"s3-backup/internal/config"
+)
+
+// EstimateResult summarizes the data a backup would transfer.
+type EstimateResult struct {
+	FileCount        int64   `json:"file_count"`
+	TotalBytes       int64   `json:"total_bytes"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// EstimateBackup computes the number of files and total bytes a backup would upload,
+// along with the projected S3 PUT request cost. Nothing is uploaded.
+// In archive mode the cost is based on one PUT per backup directory. Incremental mode
+// is ignored, so the estimate is an upper bound for an incremental backup.
+func (s *Service) EstimateBackup(ctx context.Context) (EstimateResult, error) {
+	const op = "s3.Service.EstimateBackup"
+
+	target := s.snapshotTarget()
+	files, _, err := s.collectAllFiles(ctx, target)
+	if err != nil {
+		return EstimateResult{}, fmt.Errorf("%s: failed to collect files: %w", op, err)
+	}
+
+	totalBytes, err := totalSize(ctx, files)
+	if err != nil {
+		return EstimateResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := EstimateResult{FileCount: int64(len(files)), TotalBytes: totalBytes}
+
+	puts := result.FileCount
+	if s.archiveMode == config.ArchiveModeTarGz {
+		puts = int64(len(target.dirs))
+	}
+	result.EstimatedCostUSD = float64(puts) * s.costPerPutUSD
+
+	return result, nil
+}
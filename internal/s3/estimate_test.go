@@ -0,0 +1,90 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"s3-backup/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_EstimateBackup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		recursive   bool
+		archiveMode string
+		want        EstimateResult
+	}{
+		"non-recursive counts top-level files": {
+			want: EstimateResult{FileCount: 2, TotalBytes: 8, EstimatedCostUSD: 0.02},
+		},
+		"recursive includes nested files": {
+			recursive: true,
+			want:      EstimateResult{FileCount: 3, TotalBytes: 14, EstimatedCostUSD: 0.03},
+		},
+		"archive mode costs one PUT per directory": {
+			recursive:   true,
+			archiveMode: config.ArchiveModeTarGz,
+			want:        EstimateResult{FileCount: 3, TotalBytes: 14, EstimatedCostUSD: 0.01},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "a.txt", "1234")
+			createFile(t, dir, "b.txt", "5678")
+			subdir := filepath.Join(dir, "subdir")
+			require.NoError(t, os.Mkdir(subdir, 0750))
+			createFile(t, subdir, "c.txt", "nested")
+
+			svc := &Service{
+				backupDirs:    []string{dir},
+				recursive:     tc.recursive,
+				archiveMode:   tc.archiveMode,
+				costPerPutUSD: 0.01,
+			}
+
+			got, err := svc.EstimateBackup(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want.FileCount, got.FileCount)
+			assert.Equal(t, tc.want.TotalBytes, got.TotalBytes)
+			assert.InDelta(t, tc.want.EstimatedCostUSD, got.EstimatedCostUSD, 1e-9)
+		})
+	}
+}
+
+func TestService_EstimateBackup_DoesNotUpload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "a.txt", "content")
+
+	client := &mockS3Client{}
+	svc := &Service{client: client, bucketName: "test-bucket", backupDirs: []string{dir}}
+
+	_, err := svc.EstimateBackup(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, client.putKeys)
+}
+
+func TestService_EstimateBackup_ContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Cancel immediately
+
+	svc := &Service{backupDirs: []string{t.TempDir()}}
+	_, err := svc.EstimateBackup(ctx)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -5,20 +5,34 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
+	"os"
 	"path/filepath"
+	"s3-backup/internal/config"
+	"strings"
 	"time"
 )
 
 // collectAllFiles aggregates all files from the configured backup directories.
 // If recursion is enabled, it traverses subdirectories.
-// Returns a combined list of file paths with their S3-ready prefixes.
-func (s *Service) collectAllFiles(ctx context.Context) ([]string, error) {
-	const op = "s3.Service.collectAllFiles"
+// Returns a combined list of file paths with their S3-ready prefixes, and the directories
+// without any collected file if empty directory placeholders are enabled.
+func (s *Service) collectAllFiles(ctx context.Context, target backupTarget) ([]string, []string, error) {
+	collectors, err := s.collectDirs(ctx, target)
+	files, emptyDirs := collectedFiles(collectors)
+	return files, emptyDirs, err
+}
+
+// collectDirs scans each directory of target and returns the collector of every directory
+// that could be scanned, in order, so archive mode can pack each directory from its own files.
+func (s *Service) collectDirs(ctx context.Context, target backupTarget) ([]*fileCollector, error) {
+	const op = "s3.Service.collectDirs"
 
-	recursive := s.isRecursive()
-	dirs := s.getBackupDirs()
+	recursive := target.recursive
+	dirs := target.dirs
 
-	var allFiles []string
+	var collectors []*fileCollector
+	var skipped SkipStats
 	var joinedErrs error
 
 	for _, dir := range dirs {
@@ -29,52 +43,184 @@ func (s *Service) collectAllFiles(ctx context.Context) ([]string, error) {
 		default:
 		}
 
-		files, err := s.collectFilesFromDir(ctx, dir, recursive)
+		collector, err := s.scanDir(ctx, dir, recursive)
 		if err != nil {
 			joinedErrs = errors.Join(joinedErrs, err)
 			continue
 		}
-		allFiles = append(allFiles, files...)
+		collectors = append(collectors, collector)
+		skipped.add(collector.skipped)
+	}
+
+	allFiles, _ := collectedFiles(collectors)
+	s.logger().Debug("collected files to backup",
+		"files", len(allFiles),
+		"skipped_by_path", skipped.ByPath,
+		"skipped_by_permission", skipped.ByPermission,
+		"skipped_by_lock", skipped.ByLock,
+		"skipped_by_type", skipped.ByType)
+
+	// Archives are uploaded under the directory name, so the keys of their files do not matter
+	if s.archiveMode == config.ArchiveModeNone {
+		s.warnLongKeys(target, allFiles)
 	}
 
 	if joinedErrs != nil {
-		return allFiles, fmt.Errorf("%s: encountered error(s) when attempting to collect files to backup: %w", op, joinedErrs)
+		return collectors, fmt.Errorf("%s: encountered error(s) when attempting to collect files to backup: %w", op, joinedErrs)
 	}
 
-	return allFiles, nil
+	return collectors, nil
 }
 
-// collectFilesFromDir collects all file paths from a single directory.
-// Files are prefixed with the base directory name for S3 organization.
-func (s *Service) collectFilesFromDir(ctx context.Context, dir string, recursive bool) ([]string, error) {
-	const op = "s3.Service.collectFilesFromDir"
+// collectedFiles returns the files and empty directories of all collectors combined.
+func collectedFiles(collectors []*fileCollector) ([]string, []string) {
+	var files []string
+	var emptyDirs []string
+	for _, collector := range collectors {
+		files = append(files, collector.files...)
+		emptyDirs = append(emptyDirs, collector.emptyDirs()...)
+	}
+	return files, emptyDirs
+}
+
+// collectFilesFromDir collects all file paths from a single directory, along with counts
+// of the files it skipped. Files are prefixed with the base directory name for S3 organization.
+// A relative dir is resolved against the working directory, so the returned paths are absolute.
+func (s *Service) collectFilesFromDir(ctx context.Context, dir string, recursive bool) ([]string, SkipStats, error) {
+	collector, err := s.scanDir(ctx, dir, recursive)
+	if err != nil {
+		return nil, SkipStats{}, err
+	}
+	return collector.files, collector.skipped, nil
+}
+
+// scanDir walks dir the way collectFilesFromDir describes and returns the collector holding
+// what it found.
+func (s *Service) scanDir(ctx context.Context, dir string, recursive bool) (*fileCollector, error) {
+	const op = "s3.Service.scanDir"
 
 	if dir == "" {
 		return nil, fmt.Errorf("%s: %w", op, ErrEmptyDirectory)
 	}
 
+	// Walk the absolute path so collected files never start with ./ or ../ and the base
+	// directory of "." is the name of the working directory
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to resolve absolute path of %s: %w", op, dir, err)
+	}
+
+	if s.skipUnchangedDirs && s.isUnchangedSinceBackup(absDir) {
+		s.logger().Info("skipping directory not modified since its last backup", "dir", dir)
+		return &fileCollector{dir: absDir, files: []string{}}, nil
+	}
+
+	startTime := time.Now()
 	collector := &fileCollector{
-		ctx:       ctx,
-		dir:       dir,
-		baseDir:   filepath.Base(dir),
-		recursive: recursive,
-		files:     make([]string, 0),
+		ctx:        ctx,
+		logger:     s.logger(),
+		dir:        absDir,
+		baseDir:    filepath.Base(absDir),
+		recursive:  recursive,
+		skipLocked: s.skipLockedFiles,
+		skipHidden: s.excludeHidden,
+		maxDepth:   s.maxWalkDepth,
+		files:      make([]string, 0),
+
+		trackEmptyDirs: s.emptyDirPlaceholder,
 	}
 
-	if err := filepath.WalkDir(dir, collector.walk); err != nil {
+	if err := filepath.WalkDir(absDir, collector.walk); err != nil {
 		return nil, fmt.Errorf("%s: failed to walk directory %s: %w", op, dir, err)
 	}
 
-	return collector.files, nil
+	s.logger().Debug("directory scan complete",
+		"dir", dir,
+		"files_found", len(collector.files),
+		"duration_ms", time.Since(startTime).Milliseconds())
+
+	return collector, nil
+}
+
+// isUnchangedSinceBackup reports whether the modification time of absDir is before its last
+// successful backup. The modification time of a directory changes when entries are added,
+// removed, or renamed, but not when a file inside it is modified in place.
+func (s *Service) isUnchangedSinceBackup(absDir string) bool {
+	s.lastBackupMu.Lock()
+	last, ok := s.lastBackupTime[absDir]
+	s.lastBackupMu.Unlock()
+	if !ok {
+		return false
+	}
+
+	fi, err := os.Stat(absDir)
+	if err != nil {
+		return false
+	}
+	return fi.ModTime().Before(last)
+}
+
+// recordBackupTime records timestamp as the last successful backup of dirs.
+func (s *Service) recordBackupTime(dirs []string, timestamp time.Time) {
+	s.lastBackupMu.Lock()
+	defer s.lastBackupMu.Unlock()
+
+	if s.lastBackupTime == nil {
+		s.lastBackupTime = make(map[string]time.Time, len(dirs))
+	}
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		s.lastBackupTime[absDir] = timestamp
+	}
+}
+
+// SkipStats counts the entries left out of a backup during file collection, by reason.
+type SkipStats struct {
+	// ByPath counts files and directories excluded by their path.
+	ByPath int
+	// ByPermission counts files and directories that could not be read.
+	ByPermission int
+	// ByLock counts files that were locked by another process.
+	ByLock int
+	// ByType counts named pipes, sockets, devices, and other entries that are not regular files.
+	ByType int
+}
+
+// Total returns the number of skipped entries across all reasons.
+func (s SkipStats) Total() int {
+	return s.ByPath + s.ByPermission + s.ByLock + s.ByType
+}
+
+// add merges the counts of other into s.
+func (s *SkipStats) add(other SkipStats) {
+	s.ByPath += other.ByPath
+	s.ByPermission += other.ByPermission
+	s.ByLock += other.ByLock
+	s.ByType += other.ByType
 }
 
 // fileCollector is a helper type for collecting files during directory traversal.
 type fileCollector struct {
-	ctx       context.Context
-	dir       string
-	baseDir   string
-	recursive bool
-	files     []string
+	ctx        context.Context
+	logger     *slog.Logger
+	dir        string
+	baseDir    string
+	recursive  bool
+	skipLocked bool
+	skipHidden bool
+	// maxDepth is how many directory levels below dir are entered; 0 means no limit
+	maxDepth int
+	files    []string
+	skipped  SkipStats
+
+	// trackEmptyDirs records the entered directories in dirs and those holding a collected file
+	// or entered directory in nonEmpty, see emptyDirs
+	trackEmptyDirs bool
+	dirs           []string
+	nonEmpty       map[string]bool
 }
 
 // walk is the filepath.WalkDirFunc that processes each entry during directory traversal.
@@ -88,26 +234,233 @@ func (fc *fileCollector) walk(path string, d fs.DirEntry, err error) error {
 	}
 
 	if err != nil {
+		// Skip unreadable entries below the backup directory instead of failing the whole directory
+		if errors.Is(err, fs.ErrPermission) && path != fc.dir {
+			loggerOrDefault(fc.logger).Warn("skipping unreadable path", "path", path, "error", err)
+			fc.skipped.ByPermission++
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
 		return fmt.Errorf("%s: error accessing path %s: %w", op, path, err)
 	}
 
+	// Skip dotfiles and everything below dot-directories; the backup directory itself is always included
+	if fc.skipHidden && path != fc.dir && strings.HasPrefix(d.Name(), ".") {
+		fc.skipped.ByPath++
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	}
+
 	// Skip directories
 	if d.IsDir() {
 		// If not recursive and this is a subdirectory, skip it
 		if !fc.recursive && path != fc.dir {
 			return fs.SkipDir
 		}
+		if path != fc.dir {
+			if fc.tooDeep(path) {
+				loggerOrDefault(fc.logger).Debug("skipping directory below max walk depth", "dir", path, "max_depth", fc.maxDepth)
+				fc.skipped.ByPath++
+				return fs.SkipDir
+			}
+			loggerOrDefault(fc.logger).Debug("entering directory", "dir", path)
+		}
+		fc.trackDir(path)
+		return nil
+	}
+
+	// Reading a named pipe blocks until a writer appears, and sockets and devices are no files to
+	// back up, so only regular files and symlinks are collected
+	if !d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0 {
+		loggerOrDefault(fc.logger).Warn("skipping file that is not a regular file", "path", path, "type", d.Type().String())
+		fc.skipped.ByType++
 		return nil
 	}
 
+	// Skip files that are still being written, e.g. by pg_dump, instead of uploading a partial copy.
+	// Symlinks are not checked, since opening one that points to a named pipe blocks until a writer appears.
+	if fc.skipLocked && d.Type().IsRegular() {
+		locked, err := isFileLocked(path)
+		if err != nil {
+			loggerOrDefault(fc.logger).Warn("failed to check file lock, backing it up anyway", "path", path, "error", err)
+		} else if locked {
+			loggerOrDefault(fc.logger).Warn("skipping locked file", "path", path, "file_locked", true)
+			fc.skipped.ByLock++
+			return nil
+		}
+	}
+
 	// Store the full path for file operations
 	// The S3 key will be constructed later using the base directory and relative path
 	fc.files = append(fc.files, path)
+	fc.trackFile(path)
+	return nil
+}
+
+// trackDir records the entered directory path and marks its parent as not empty.
+// It is a no-op unless trackEmptyDirs is set.
+func (fc *fileCollector) trackDir(path string) {
+	if !fc.trackEmptyDirs {
+		return
+	}
+
+	fc.dirs = append(fc.dirs, path)
+	if path != fc.dir {
+		fc.markNonEmpty(filepath.Dir(path))
+	}
+}
+
+// trackFile marks the directory of the collected file path as not empty.
+// It is a no-op unless trackEmptyDirs is set.
+func (fc *fileCollector) trackFile(path string) {
+	if !fc.trackEmptyDirs {
+		return
+	}
+
+	fc.markNonEmpty(filepath.Dir(path))
+}
+
+// markNonEmpty records that dir holds a collected file or entered directory.
+func (fc *fileCollector) markNonEmpty(dir string) {
+	if fc.nonEmpty == nil {
+		fc.nonEmpty = make(map[string]bool)
+	}
+	fc.nonEmpty[dir] = true
+}
+
+// emptyDirs returns the entered directories that hold no collected file or entered
+// directory, in walk order. A directory whose files were all skipped counts as empty.
+func (fc *fileCollector) emptyDirs() []string {
+	var empty []string
+	for _, dir := range fc.dirs {
+		if !fc.nonEmpty[dir] {
+			empty = append(empty, dir)
+		}
+	}
+	return empty
+}
+
+// tooDeep reports whether the directory at path is more than maxDepth levels below dir,
+// e.g. dir/a/b/c is three levels below dir.
+func (fc *fileCollector) tooDeep(path string) bool {
+	if fc.maxDepth <= 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(fc.dir, path)
+	if err != nil {
+		return false
+	}
+	return strings.Count(rel, string(filepath.Separator))+1 > fc.maxDepth
+}
+
+// Layouts of the timestamp prefix shared by all objects of one backup. dateLayout is used
+// instead of timestampLayout when prefixes are date only, so backups of the same day share one.
+const (
+	timestampLayout = "2006-01-02T15-04-05"
+	dateLayout      = "2006-01-02"
+)
+
+// buildObjectKey constructs the S3 object key with a timestamp prefix formatted with layout.
+// Format: YYYY-MM-DDTHH-MM-SS{sep}filename, where the slashes of filename are replaced by sep too.
+// The prefix is formatted in the location of ts and carries no offset, so callers must pass
+// times in the location the prefixes are parsed in, which is local time for Backup.
+func buildObjectKey(fn, sep, layout string, ts time.Time) string {
+	if sep != "/" {
+		fn = strings.ReplaceAll(fn, "/", sep)
+	}
+	return fmt.Sprintf("%s%s%s", ts.Format(layout), sep, fn)
+}
+
+// prefixLayout returns the layout of the timestamp prefix of the object keys built by the service.
+func (s *Service) prefixLayout() string {
+	if s.prefixDateOnly {
+		return dateLayout
+	}
+	return timestampLayout
+}
+
+// parseBackupPrefix parses name, a top-level prefix without its separator, in local time, which
+// Backup formats timestamps in. Prefixes of both layouts are accepted, so backups made before
+// the layout changed are still found. start and last bound the times the backups under the
+// prefix were started at: both are the timestamp of a timestampLayout prefix, while a
+// dateLayout prefix holds the backups of the whole day, from midnight to its last instant.
+func parseBackupPrefix(name string) (start, last time.Time, err error) {
+	if ts, err := time.ParseInLocation(timestampLayout, name, time.Local); err == nil {
+		return ts, ts, nil
+	}
+
+	day, err := time.ParseInLocation(dateLayout, name, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return day, day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// keySeparator returns the separator of the object keys built by the service.
+func (s *Service) keySeparator() string {
+	if s.objectKeySeparator == "" {
+		return config.DefaultObjectKeySeparator
+	}
+	return s.objectKeySeparator
+}
+
+// checkKeyLength returns ErrObjectKeyTooLong if key is longer than S3 accepts, which a deeply
+// nested file with long directory names can cause.
+func (s *Service) checkKeyLength(key string) error {
+	const op = "s3.Service.checkKeyLength"
+
+	limit := s.maxKeyLength
+	if limit <= 0 {
+		limit = config.MaxObjectKeyLength
+	}
+
+	if len(key) > limit {
+		return fmt.Errorf("%s: %w: %d bytes exceeds the limit of %d (set %s)",
+			op, ErrObjectKeyTooLong, len(key), limit, config.EnvMaxObjectKeyLength)
+	}
 	return nil
 }
 
-// buildObjectKey constructs the S3 object key with a timestamp prefix.
-// Format: YYYY-MM-DDTHH-MM-SS/filename
-func buildObjectKey(fn string, ts time.Time) string {
-	return fmt.Sprintf("%s/%s", ts.Format("2006-01-02T15-04-05"), fn)
+// warnLongKeys logs a warning for every file whose object key would be too long, so the
+// problem shows up when files are collected instead of only when their upload fails.
+func (s *Service) warnLongKeys(target backupTarget, files []string) {
+	for _, file := range files {
+		s3Key, err := s.buildS3Key(target, file)
+		if err != nil {
+			continue
+		}
+
+		// All timestamp prefixes have the same length, so any timestamp gives the final key length
+		if err := s.checkKeyLength(buildObjectKey(s3Key, s.keySeparator(), s.prefixLayout(), time.Time{})); err != nil {
+			s.logger().Warn("file will not be backed up: object key too long", "file", file, "error", err)
+		}
+	}
+}
+
+// totalSize returns the combined size in bytes of the given files.
+func totalSize(ctx context.Context, files []string) (int64, error) {
+	const op = "s3.totalSize"
+
+	var total int64
+	for _, file := range files {
+		// Check for context cancellation
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("%s: %w", op, ctx.Err())
+		default:
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return 0, fmt.Errorf("%s: failed to stat file %s: %w", op, file, err)
+		}
+		total += info.Size()
+	}
+
+	return total, nil
 }
@@ -0,0 +1,53 @@
+//go:build linux
+
+package s3
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectFilesFromDir_SkipsNamedPipes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+	pipe := filepath.Join(dir, "pipe")
+	require.NoError(t, syscall.Mkfifo(pipe, 0600))
+
+	logs := &logRecorder{}
+	client := &mockS3Client{}
+	svc := &Service{
+		client:     client,
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		log:        slog.New(logs),
+	}
+
+	files, skipped, err := svc.collectFilesFromDir(context.Background(), dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "file.txt")}, files)
+	assert.Equal(t, SkipStats{ByType: 1}, skipped)
+
+	record, ok := logs.find("skipping file that is not a regular file")
+	require.True(t, ok)
+	assert.Equal(t, pipe, recordAttrs(record)["path"])
+
+	// Reading the pipe would block the backup until a writer appears
+	done := make(chan error, 1)
+	go func() { done <- svc.Backup(context.Background()) }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("backing up a directory with a named pipe blocked")
+	}
+	assert.Len(t, client.putKeys, 1)
+}
@@ -2,8 +2,12 @@ package s3
 
 import (
 	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -114,7 +118,7 @@ func TestCollectFilesFromDir(t *testing.T) {
 				recursive:  recursive,
 			}
 
-			files, err := svc.collectFilesFromDir(ctx, dir, recursive)
+			files, skipped, err := svc.collectFilesFromDir(ctx, dir, recursive)
 
 			if tc.wantErr != nil {
 				require.Error(t, err)
@@ -124,6 +128,7 @@ func TestCollectFilesFromDir(t *testing.T) {
 
 			require.NoError(t, err)
 			assert.Len(t, files, tc.wantMinFiles)
+			assert.Zero(t, skipped.Total())
 
 			// Verify files are prefixed with base directory name
 			if len(files) > 0 {
@@ -150,12 +155,452 @@ func TestCollectFilesFromDir_ContextCancellation(t *testing.T) {
 		recursive:  false,
 	}
 
-	_, err := svc.collectFilesFromDir(ctx, dir, false)
+	_, _, err := svc.collectFilesFromDir(ctx, dir, false)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
 }
 
+func TestCollectFilesFromDir_LogsScan(t *testing.T) {
+	logs := captureLogs(t)
+
+	dir := t.TempDir()
+	createFile(t, dir, "file1.txt", "content1")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0750))
+	createFile(t, filepath.Join(dir, "subdir"), "file2.txt", "content2")
+
+	svc := &Service{}
+	_, _, err := svc.collectFilesFromDir(context.Background(), dir, true)
+	require.NoError(t, err)
+
+	record, ok := logs.find("directory scan complete")
+	require.True(t, ok, "expected scan completion to be logged")
+	attrs := recordAttrs(record)
+	assert.Equal(t, dir, attrs["dir"])
+	assert.Equal(t, int64(2), attrs["files_found"])
+	assert.Contains(t, attrs, "duration_ms")
+
+	record, ok = logs.find("entering directory")
+	require.True(t, ok, "expected subdirectory descent to be logged")
+	assert.Equal(t, filepath.Join(dir, "subdir"), recordAttrs(record)["dir"])
+}
+
+func TestFileCollector_SkipsUnreadablePaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "readable.txt", "content")
+	subdir := filepath.Join(dir, "locked")
+	require.NoError(t, os.Mkdir(subdir, 0750))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	collector := &fileCollector{ctx: context.Background(), dir: dir, recursive: true}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+
+		var walkErr error
+		if entry.IsDir() {
+			// Simulate the error WalkDir reports for a directory it cannot read
+			walkErr = &fs.PathError{Op: "open", Path: path, Err: fs.ErrPermission}
+		}
+
+		err := collector.walk(path, entry, walkErr)
+		if entry.IsDir() {
+			assert.ErrorIs(t, err, fs.SkipDir)
+			continue
+		}
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{filepath.Join(dir, "readable.txt")}, collector.files)
+	assert.Equal(t, SkipStats{ByPermission: 1}, collector.skipped)
+}
+
+func TestFileCollector_UnreadableRootFails(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	collector := &fileCollector{ctx: context.Background(), dir: dir}
+
+	err := collector.walk(dir, nil, &fs.PathError{Op: "lstat", Path: dir, Err: fs.ErrPermission})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Zero(t, collector.skipped.Total())
+}
+
+func TestCollectFilesFromDir_SkippedByPermission(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can read directories regardless of their permissions")
+	}
+
+	dir := t.TempDir()
+	createFile(t, dir, "readable.txt", "content")
+	locked := filepath.Join(dir, "locked")
+	require.NoError(t, os.Mkdir(locked, 0750))
+	createFile(t, locked, "hidden.txt", "content")
+	require.NoError(t, os.Chmod(locked, 0))
+	t.Cleanup(func() { _ = os.Chmod(locked, 0750) }) //nolint:gosec // G302: restore permissions for cleanup
+
+	svc := &Service{backupDirs: []string{dir}, recursive: true}
+	files, skipped, err := svc.collectFilesFromDir(context.Background(), dir, true)
+
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, 1, skipped.ByPermission)
+}
+
+func TestCollectFilesFromDir_ExcludeHidden(t *testing.T) {
+	t.Parallel()
+
+	// The backup directory itself may be hidden
+	dir := filepath.Join(t.TempDir(), ".home")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ssh"), 0750))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "documents", ".cache"), 0750))
+	createFile(t, filepath.Join(dir, ".ssh"), "id_rsa", "key")
+	createFile(t, filepath.Join(dir, "documents"), "report.pdf", "report")
+	createFile(t, filepath.Join(dir, "documents"), ".lock", "lock")
+	createFile(t, filepath.Join(dir, "documents", ".cache"), "thumb.png", "thumb")
+
+	tc := map[string]struct {
+		excludeHidden bool
+		wantFiles     []string
+		wantSkipped   int
+	}{
+		"hidden entries excluded": {
+			excludeHidden: true,
+			wantFiles:     []string{filepath.Join(dir, "documents", "report.pdf")},
+			wantSkipped:   3,
+		},
+		"hidden entries included by default": {
+			wantFiles: []string{
+				filepath.Join(dir, ".ssh", "id_rsa"),
+				filepath.Join(dir, "documents", ".cache", "thumb.png"),
+				filepath.Join(dir, "documents", ".lock"),
+				filepath.Join(dir, "documents", "report.pdf"),
+			},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{excludeHidden: tc.excludeHidden}
+			files, skipped, err := svc.collectFilesFromDir(context.Background(), dir, true)
+
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantFiles, files)
+			assert.Equal(t, tc.wantSkipped, skipped.ByPath)
+		})
+	}
+}
+
+// TestService_RelativeDirs cannot run in parallel because it changes the working directory.
+func TestCollectFilesFromDir_MaxWalkDepth(t *testing.T) {
+	t.Parallel()
+
+	// dir/f0, dir/l1/f1, dir/l1/l2/f2, ... down to dir/l1/l2/l3/l4/l5/f5
+	dir := t.TempDir()
+	levels := []string{dir}
+	for i := 1; i <= 5; i++ {
+		levels = append(levels, filepath.Join(levels[i-1], fmt.Sprintf("l%d", i)))
+	}
+	require.NoError(t, os.MkdirAll(levels[5], 0750))
+	for i, level := range levels {
+		createFile(t, level, fmt.Sprintf("f%d", i), "data")
+	}
+	filesUpTo := func(depth int) []string {
+		var files []string
+		for i := range depth + 1 {
+			files = append(files, filepath.Join(levels[i], fmt.Sprintf("f%d", i)))
+		}
+		return files
+	}
+
+	tc := map[string]struct {
+		maxWalkDepth int
+		wantFiles    []string
+		wantSkipped  int
+	}{
+		"unlimited by default": {
+			wantFiles: filesUpTo(5),
+		},
+		"depth 2": {
+			maxWalkDepth: 2,
+			wantFiles:    filesUpTo(2),
+			wantSkipped:  1,
+		},
+		"depth 1": {
+			maxWalkDepth: 1,
+			wantFiles:    filesUpTo(1),
+			wantSkipped:  1,
+		},
+		"depth at the tree depth": {
+			maxWalkDepth: 5,
+			wantFiles:    filesUpTo(5),
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{maxWalkDepth: tc.maxWalkDepth}
+			files, skipped, err := svc.collectFilesFromDir(context.Background(), dir, true)
+
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantFiles, files)
+			assert.Equal(t, tc.wantSkipped, skipped.ByPath)
+		})
+	}
+}
+
+func TestService_RelativeDirs(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "workdir")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "tmpdir", "sub"), 0750))
+	createFile(t, filepath.Join(root, "tmpdir"), "a.txt", "a")
+	createFile(t, filepath.Join(root, "tmpdir", "sub"), "b.txt", "b")
+	t.Chdir(root)
+
+	tc := map[string]struct {
+		dir      string
+		wantKeys []string
+	}{
+		"working directory": {
+			dir:      ".",
+			wantKeys: []string{"workdir/tmpdir/a.txt", "workdir/tmpdir/sub/b.txt"},
+		},
+		"relative subdirectory": {
+			dir:      "./tmpdir",
+			wantKeys: []string{"tmpdir/a.txt", "tmpdir/sub/b.txt"},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, validateDirectories([]string{tc.dir}))
+
+			client := &mockS3Client{}
+			svc := &Service{client: client, bucketName: "test-bucket", backupDirs: []string{tc.dir}, recursive: true}
+			target := svc.snapshotTarget()
+
+			files, _, err := svc.collectFilesFromDir(context.Background(), tc.dir, true)
+			require.NoError(t, err)
+			for _, file := range files {
+				assert.True(t, filepath.IsAbs(file), "collected file %s should be absolute", file)
+			}
+
+			ts := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+			require.NoError(t, svc.backupAllFiles(context.Background(), target, files, ts))
+
+			var gotKeys []string
+			for _, key := range client.putKeys {
+				assert.NotContains(t, key, "./")
+				assert.NotContains(t, key, strings.TrimPrefix(filepath.ToSlash(root), "/"))
+				gotKeys = append(gotKeys, strings.TrimPrefix(key, "2025-12-15T14-30-00/"))
+			}
+			assert.ElementsMatch(t, tc.wantKeys, gotKeys)
+		})
+	}
+}
+
+func TestService_ObjectKeyTooLong(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		maxKeyLength int
+		nameLen      int
+		wantErr      bool
+	}{
+		"short key": {
+			nameLen: 10,
+		},
+		"key over the S3 limit": {
+			nameLen: 250,
+			wantErr: true,
+		},
+		"key over a configured limit": {
+			maxKeyLength: 100,
+			nameLen:      50,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			segment := strings.Repeat("d", tc.nameLen)
+			dir := filepath.Join(root, segment, segment, segment, segment, segment)
+			require.NoError(t, os.MkdirAll(dir, 0750))
+			createFile(t, dir, "file.txt", "content")
+
+			logs := &logRecorder{}
+			client := &mockS3Client{}
+			svc := &Service{
+				client:       client,
+				bucketName:   "test-bucket",
+				backupDirs:   []string{root},
+				recursive:    true,
+				maxKeyLength: tc.maxKeyLength,
+				log:          slog.New(logs),
+			}
+			target := svc.snapshotTarget()
+
+			files, _, err := svc.collectAllFiles(ctx, target)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			_, warned := logs.find("file will not be backed up: object key too long")
+			assert.Equal(t, tc.wantErr, warned)
+
+			err = svc.backupFile(ctx, target, files[0], time.Now())
+			if !tc.wantErr {
+				require.NoError(t, err)
+				assert.Len(t, client.putKeys, 1)
+				return
+			}
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrObjectKeyTooLong)
+			var backupErr *BackupError
+			require.ErrorAs(t, err, &backupErr)
+			assert.Equal(t, files[0], backupErr.FilePath)
+			assert.Empty(t, client.putKeys, "no upload should be attempted")
+		})
+	}
+}
+
+func TestService_Backup_SkipUnchangedDirs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+
+	tc := map[string]struct {
+		skipUnchangedDirs bool
+		wantSecondPuts    int
+	}{
+		"unchanged directory skipped": {
+			skipUnchangedDirs: true,
+			wantSecondPuts:    1,
+		},
+		"disabled": {
+			wantSecondPuts: 2,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			unchanged, changed := t.TempDir(), t.TempDir()
+			createFile(t, unchanged, "old.txt", "old")
+			createFile(t, changed, "new.txt", "new")
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:            client,
+				bucketName:        "test-bucket",
+				backupDirs:        []string{unchanged, changed},
+				skipUnchangedDirs: tc.skipUnchangedDirs,
+				nowFunc:           func() time.Time { return now },
+			}
+
+			// The first backup has no previous backup to compare against
+			require.NoError(t, os.Chtimes(unchanged, now.Add(-time.Hour), now.Add(-time.Hour)))
+			require.NoError(t, os.Chtimes(changed, now.Add(-time.Hour), now.Add(-time.Hour)))
+			require.NoError(t, svc.Backup(ctx))
+			require.Len(t, client.putKeys, 2)
+
+			require.NoError(t, os.Chtimes(changed, now.Add(time.Hour), now.Add(time.Hour)))
+			require.NoError(t, svc.Backup(ctx))
+
+			second := client.putKeys[2:]
+			assert.Len(t, second, tc.wantSecondPuts)
+			assert.Contains(t, strings.Join(second, ","), "new.txt")
+		})
+	}
+}
+
+func TestService_Backup_SkipUnchangedDirs_FailedBackup(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	require.NoError(t, os.Chtimes(dir, now.Add(-time.Hour), now.Add(-time.Hour)))
+
+	client := &mockS3Client{shouldFail: true}
+	svc := &Service{
+		client:            client,
+		bucketName:        "test-bucket",
+		backupDirs:        []string{dir},
+		skipUnchangedDirs: true,
+		nowFunc:           func() time.Time { return now },
+	}
+
+	require.Error(t, svc.Backup(context.Background()))
+
+	// A failed backup is not recorded, so the directory is collected again
+	files, _, err := svc.collectFilesFromDir(context.Background(), dir, false)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestSkipStats(t *testing.T) {
+	t.Parallel()
+
+	stats := SkipStats{ByPath: 1, ByLock: 2}
+	stats.add(SkipStats{ByPath: 3, ByPermission: 4, ByLock: 1, ByType: 2})
+
+	assert.Equal(t, SkipStats{ByPath: 4, ByPermission: 4, ByLock: 3, ByType: 2}, stats)
+	assert.Equal(t, 13, stats.Total())
+}
+
+func TestCollectAllFiles_LogsSkipStats(t *testing.T) {
+	t.Parallel()
+
+	dir1 := t.TempDir()
+	createFile(t, dir1, "report.pdf", "content")
+	createFile(t, dir1, ".lock", "content")
+
+	dir2 := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir2, ".cache"), 0750))
+	createFile(t, filepath.Join(dir2, ".cache"), "thumb.png", "content")
+	createFile(t, dir2, "notes.txt", "content")
+
+	logs := &logRecorder{}
+	svc := &Service{
+		backupDirs:    []string{dir1, dir2},
+		recursive:     true,
+		excludeHidden: true,
+		log:           slog.New(logs),
+	}
+
+	files, _, err := svc.collectAllFiles(context.Background(), svc.snapshotTarget())
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	record, ok := logs.find("collected files to backup")
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{
+		"files":                 int64(2),
+		"skipped_by_path":       int64(2),
+		"skipped_by_permission": int64(0),
+		"skipped_by_lock":       int64(0),
+		"skipped_by_type":       int64(0),
+	}, recordAttrs(record))
+}
+
 func TestCollectAllFiles(t *testing.T) {
 	t.Parallel()
 
@@ -226,7 +671,7 @@ func TestCollectAllFiles(t *testing.T) {
 			t.Parallel()
 
 			svc := tc.setup(t)
-			files, err := svc.collectAllFiles(ctx)
+			files, _, err := svc.collectAllFiles(ctx, svc.snapshotTarget())
 
 			if tc.wantErr {
 				require.Error(t, err)
@@ -253,7 +698,7 @@ func TestCollectAllFiles_ContextCancellation(t *testing.T) {
 		recursive:  false,
 	}
 
-	_, err := svc.collectAllFiles(ctx)
+	_, _, err := svc.collectAllFiles(ctx, svc.snapshotTarget())
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -389,6 +834,7 @@ func TestBuildObjectKey(t *testing.T) {
 
 	tc := map[string]struct {
 		fileName string
+		sep      string
 		ts       time.Time
 		want     string
 	}{
@@ -407,19 +853,92 @@ func TestBuildObjectKey(t *testing.T) {
 			ts:       time.Date(2025, 6, 15, 14, 22, 33, 0, time.UTC),
 			want:     "2025-06-15T14-22-33/my file.txt",
 		},
+		"non-UTC location": {
+			fileName: "file.txt",
+			ts:       time.Date(2025, 12, 15, 10, 30, 45, 0, time.FixedZone("EST", -5*60*60)),
+			want:     "2025-12-15T10-30-45/file.txt",
+		},
+		"non-UTC location across midnight": {
+			fileName: "file.txt",
+			ts:       time.Date(2025, 12, 31, 23, 0, 0, 0, time.FixedZone("EST", -5*60*60)),
+			want:     "2025-12-31T23-00-00/file.txt",
+		},
+		"underscore separator": {
+			fileName: "docs/file.txt",
+			sep:      "_",
+			ts:       time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			want:     "2025-06-01T12-00-00_docs_file.txt",
+		},
+		"multi-character separator": {
+			fileName: "dir/subdir/file.log",
+			sep:      "__",
+			ts:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:     "2025-01-01T00-00-00__dir__subdir__file.log",
+		},
 	}
 
 	for name, tc := range tc {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			result := buildObjectKey(tc.fileName, tc.ts)
+			sep := tc.sep
+			if sep == "" {
+				sep = "/"
+			}
+			result := buildObjectKey(tc.fileName, sep, timestampLayout, tc.ts)
 
 			assert.Equal(t, tc.want, result)
 		})
 	}
 }
 
+func TestParseBackupPrefix(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+	ts := time.Date(2025, 6, 1, 14, 30, 0, 0, time.Local)
+
+	tc := map[string]struct {
+		name      string
+		wantStart time.Time
+		wantLast  time.Time
+		wantErr   bool
+	}{
+		"timestamp":  {name: "2025-06-01T14-30-00", wantStart: ts, wantLast: ts},
+		"date only":  {name: "2025-06-01", wantStart: day, wantLast: day.AddDate(0, 0, 1).Add(-time.Nanosecond)},
+		"not a date": {name: "_latest", wantErr: true},
+		"other path": {name: "manual", wantErr: true},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			start, last, err := parseBackupPrefix(tc.name)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tc.wantStart.Equal(start), "start %v", start)
+			assert.True(t, tc.wantLast.Equal(last), "last %v", last)
+		})
+	}
+}
+
+func TestBuildObjectKey_SameInstantDifferentLocations(t *testing.T) {
+	t.Parallel()
+
+	utc := time.Date(2025, 12, 15, 15, 30, 45, 0, time.UTC)
+	est := utc.In(time.FixedZone("EST", -5*60*60))
+	require.True(t, utc.Equal(est))
+
+	// The prefix has no offset, so the same instant gets a different prefix in every location
+	assert.Equal(t, "2025-12-15T15-30-45/file.txt", buildObjectKey("file.txt", "/", timestampLayout, utc))
+	assert.Equal(t, "2025-12-15T10-30-45/file.txt", buildObjectKey("file.txt", "/", timestampLayout, est))
+}
+
 // createFile creates a file with the given content in the specified directory.
 func createFile(t *testing.T, dir, name, content string) {
 	t.Helper()
@@ -32,7 +32,7 @@ func FuzzBuildObjectKey(f *testing.F) {
 		}
 
 		ts := time.Unix(unixTime, 0)
-		key := buildObjectKey(filename, ts)
+		key := buildObjectKey(filename, "/", timestampLayout, ts)
 
 		expectedPrefix := ts.Format("2006-01-02T15-04-05")
 		if !strings.Contains(key, expectedPrefix) {
@@ -73,7 +73,7 @@ func FuzzCollectFilesFromDir(f *testing.F) {
 			recursive: recursive,
 		}
 
-		files, err := svc.collectFilesFromDir(context.Background(), tmpDir, recursive)
+		files, _, err := svc.collectFilesFromDir(context.Background(), tmpDir, recursive)
 		if err != nil {
 			t.Logf("collectFilesFromDir returned error: %v", err)
 		}
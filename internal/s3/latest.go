@@ -0,0 +1,62 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Key of the object pointing to the newest backup, joined by the object key separator. The
+// prefix is not a backup timestamp, so ListBackupSessions and PruneOldBackups skip it.
+const (
+	latestPrefix      = "_latest"
+	latestPointerName = "pointer.json"
+)
+
+// latestPointer is the JSON document written by updateLatestPointer.
+type latestPointer struct {
+	// LatestBackup is the timestamp prefix of the newest backup, e.g. 2025-12-15T14-30-00
+	LatestBackup string    `json:"latest_backup"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// latestPointerKey returns the key of the object updated by updateLatestPointer, e.g.
+// _latest/pointer.json.
+func (s *Service) latestPointerKey() string {
+	return latestPrefix + s.keySeparator() + latestPointerName
+}
+
+// updateLatestPointer overwrites the latest pointer object with timestamp, the prefix of the
+// backup that just completed, so restore scripts can find the newest backup at a fixed key.
+func (s *Service) updateLatestPointer(ctx context.Context, timestamp string) error {
+	const op = "s3.Service.updateLatestPointer"
+
+	body, err := json.Marshal(latestPointer{LatestBackup: timestamp, UpdatedAt: s.now().UTC()})
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode pointer: %w", op, err)
+	}
+
+	key := s.latestPointerKey()
+	input := &s3.PutObjectInput{
+		Bucket:        &s.bucketName,
+		Key:           &key,
+		Body:          bytes.NewReader(body),
+		ContentLength: aws.Int64(int64(len(body))),
+		ContentType:   aws.String("application/json"),
+		RequestPayer:  s.requestPayer(),
+	}
+	// Object Lock is not applied, since the pointer is overwritten by every backup
+	s.applyEncryption(input)
+
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("%s: failed to upload %s: %w", op, key, err)
+	}
+
+	s.logger().Debug("updated latest backup pointer", "key", key, "latest_backup", timestamp)
+	return nil
+}
@@ -0,0 +1,156 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"s3-backup/internal/config"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_UpdateLatestPointer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 12, 15, 14, 30, 5, 0, time.UTC)
+
+	tc := map[string]struct {
+		separator string
+		wantKey   string
+	}{
+		"default separator": {wantKey: "_latest/pointer.json"},
+		"custom separator":  {separator: "__", wantKey: "_latest__pointer.json"},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &bodyS3Client{}
+			svc := &Service{
+				client:             client,
+				bucketName:         "test-bucket",
+				objectKeySeparator: tc.separator,
+				kmsKeyID:           "alias/backups",
+				nowFunc:            func() time.Time { return now },
+			}
+
+			require.NoError(t, svc.updateLatestPointer(context.Background(), "2025-12-15T14-30-00"))
+
+			require.Len(t, client.putInputs, 1)
+			input := client.putInputs[0]
+			assert.Equal(t, tc.wantKey, aws.ToString(input.Key))
+			assert.Equal(t, "application/json", aws.ToString(input.ContentType))
+			assert.Equal(t, types.ServerSideEncryptionAwsKms, input.ServerSideEncryption)
+			assert.JSONEq(t, `{"latest_backup":"2025-12-15T14-30-00","updated_at":"2025-12-15T14:30:05Z"}`, client.bodies[tc.wantKey])
+		})
+	}
+}
+
+func TestService_UpdateLatestPointer_Error(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{client: &mockS3Client{shouldFail: true}, bucketName: "test-bucket"}
+
+	err := svc.updateLatestPointer(context.Background(), "2025-12-15T14-30-00")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errMockS3Failure)
+	assert.Contains(t, err.Error(), "_latest/pointer.json")
+}
+
+func TestService_Backup_LatestPointer(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2025, 12, 15, 14, 30, 0, 0, time.Local)
+
+	tc := map[string]struct {
+		updateLatest bool
+		archiveMode  string
+		failFile     bool
+		wantPointer  bool
+	}{
+		"enabled":              {updateLatest: true, wantPointer: true},
+		"enabled with archive": {updateLatest: true, archiveMode: config.ArchiveModeTarGz, wantPointer: true},
+		"disabled":             {},
+		"failed backup":        {updateLatest: true, failFile: true},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "good.txt", "content")
+			createFile(t, dir, "bad.txt", "fails")
+
+			client := &bodyS3Client{}
+			if tc.failFile {
+				client.failKey = func(key string) bool { return strings.HasSuffix(key, "bad.txt") }
+			}
+			svc := &Service{
+				client:       client,
+				bucketName:   "test-bucket",
+				backupDirs:   []string{dir},
+				archiveMode:  tc.archiveMode,
+				tempDir:      t.TempDir(),
+				updateLatest: tc.updateLatest,
+				nowFunc:      func() time.Time { return ts },
+			}
+
+			err := svc.Backup(context.Background())
+			if tc.failFile {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			i := slices.Index(client.putKeys, "_latest/pointer.json")
+			if !tc.wantPointer {
+				assert.Equal(t, -1, i)
+				return
+			}
+
+			// The pointer is only moved once every object of the backup is uploaded
+			require.Equal(t, len(client.putKeys)-1, i)
+			var pointer latestPointer
+			require.NoError(t, json.Unmarshal([]byte(client.bodies["_latest/pointer.json"]), &pointer))
+			assert.Equal(t, ts.Format(timestampLayout), pointer.LatestBackup)
+			assert.True(t, strings.HasPrefix(client.putKeys[0], pointer.LatestBackup+"/"))
+		})
+	}
+}
+
+// bodyS3Client records the body of every uploaded object by its key.
+type bodyS3Client struct {
+	mockS3Client
+
+	bodies map[string]string
+}
+
+func (c *bodyS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if c.failKey != nil && c.failKey(aws.ToString(params.Key)) {
+		return c.mockS3Client.PutObject(ctx, params, optFns...)
+	}
+
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.bodies == nil {
+		c.bodies = make(map[string]string)
+	}
+	c.bodies[aws.ToString(params.Key)] = string(body)
+	c.mu.Unlock()
+
+	params.Body = strings.NewReader(string(body))
+	return c.mockS3Client.PutObject(ctx, params, optFns...)
+}
@@ -0,0 +1,115 @@
+//go:build linux
+
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Backup_SkipsLockedFiles(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		skipLockedFiles bool
+		wantFiles       []string
+	}{
+		"locked file is skipped": {
+			skipLockedFiles: true,
+			wantFiles:       []string{"unlocked.txt"},
+		},
+		"locked file is backed up when not enabled": {
+			wantFiles: []string{"locked.txt", "unlocked.txt"},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "locked.txt", "still being written")
+			createFile(t, dir, "unlocked.txt", "content")
+			lockFile(t, filepath.Join(dir, "locked.txt"))
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:          client,
+				bucketName:      "test-bucket",
+				backupDirs:      []string{dir},
+				skipLockedFiles: tc.skipLockedFiles,
+			}
+
+			require.NoError(t, svc.Backup(context.Background()))
+
+			var got []string
+			for _, key := range client.putKeys {
+				got = append(got, filepath.Base(key))
+			}
+			assert.ElementsMatch(t, tc.wantFiles, got)
+		})
+	}
+}
+
+func TestIsFileLocked(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+	path := filepath.Join(dir, "file.txt")
+
+	locked, err := isFileLocked(path)
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	lockFile(t, path)
+
+	locked, err = isFileLocked(path)
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	_, err = isFileLocked(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestCollectFilesFromDir_SkipLockedFiles_NamedPipe(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+	require.NoError(t, syscall.Mkfifo(filepath.Join(dir, "pipe"), 0600))
+
+	svc := &Service{skipLockedFiles: true}
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := svc.collectFilesFromDir(context.Background(), dir, false)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("checking the lock of a named pipe blocked the walk")
+	}
+}
+
+// lockFile holds an exclusive flock on path until the test ends, like a process still writing it.
+func lockFile(t *testing.T, path string) {
+	t.Helper()
+
+	//nolint:gosec // G304: path is a test temp file
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+
+	//nolint:gosec // G115: file descriptors fit in an int
+	require.NoError(t, syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB))
+}
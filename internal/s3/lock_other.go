@@ -0,0 +1,8 @@
+//go:build !linux
+
+package s3
+
+// isFileLocked always reports false; lock detection is only supported on Linux.
+func isFileLocked(string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,67 @@
+package s3
+
+import (
+	"io/fs"
+	"mime"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// maxMetadataBytes is the largest total size of user-defined metadata S3 accepts,
+// measured as the sum of the lengths of all keys and values as sent.
+const maxMetadataBytes = 2 * 1024
+
+// Keys of the file metadata attached to uploads. The SDK sends them as lowercase
+// x-amz-meta-* headers, so they are stored without that prefix.
+const (
+	metadataOriginalPath = "original-path"
+	metadataMTime        = "mtime"
+	metadataMode         = "mode"
+	metadataSize         = "size"
+)
+
+// applyFileMetadata records the original path, modification time, permissions, and size of
+// the file as object metadata when file metadata is enabled. Metadata is sent as HTTP headers,
+// so a path outside ASCII is encoded as described in RFC 2047, e.g. =?utf-8?q?caf=C3=A9?=.
+// If the metadata would exceed maxMetadataBytes, which only a very long path can cause, the
+// original path is left out.
+func (s *Service) applyFileMetadata(input *s3.PutObjectInput, path string, info fs.FileInfo) {
+	if !s.fileMetadata {
+		return
+	}
+
+	metadata := map[string]string{
+		metadataOriginalPath: mime.QEncoding.Encode("utf-8", path),
+		metadataMTime:        info.ModTime().UTC().Format(time.RFC3339),
+		metadataMode:         strconv.FormatUint(uint64(info.Mode().Perm()), 8),
+		metadataSize:         strconv.FormatInt(info.Size(), 10),
+	}
+	if totalMetadataBytes(metadata) > maxMetadataBytes {
+		delete(metadata, metadataOriginalPath)
+	}
+
+	input.Metadata = metadata
+}
+
+// totalMetadataBytes returns the size S3 counts against maxMetadataBytes.
+func totalMetadataBytes(metadata map[string]string) int {
+	size := 0
+	for key, value := range metadata {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// applyContentDisposition sets the Content-Disposition of the object to the configured type
+// with fileName as the name browsers save a download under, e.g. attachment; filename=report.pdf.
+// Names that need it are quoted or, outside ASCII, encoded as described in RFC 2231.
+func (s *Service) applyContentDisposition(input *s3.PutObjectInput, fileName string) {
+	if s.contentDisposition == "" {
+		return
+	}
+
+	disposition := mime.FormatMediaType(s.contentDisposition, map[string]string{"filename": fileName})
+	input.ContentDisposition = &disposition
+}
@@ -0,0 +1,165 @@
+package s3
+
+import (
+	"context"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BackupFile_FileMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mtime := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	tc := map[string]struct {
+		fileMetadata bool
+		dirName      string
+		wantPath     bool
+	}{
+		"disabled": {
+			dirName: "docs",
+		},
+		"enabled": {
+			fileMetadata: true,
+			dirName:      "docs",
+			wantPath:     true,
+		},
+		"original path dropped when over 2 KB": {
+			fileMetadata: true,
+			dirName:      strings.Repeat(strings.Repeat("d", 200)+"/", 11),
+		},
+		"non-ASCII original path encoded": {
+			fileMetadata: true,
+			dirName:      "dokumente/übersicht",
+			wantPath:     true,
+		},
+		"original path dropped when encoded form is over 2 KB": {
+			fileMetadata: true,
+			dirName:      strings.Repeat(strings.Repeat("ü", 100)+"/", 7),
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := filepath.Join(t.TempDir(), tc.dirName)
+			require.NoError(t, os.MkdirAll(dir, 0750))
+			filePath := filepath.Join(dir, "report.txt")
+			createFile(t, dir, "report.txt", "hello")
+			require.NoError(t, os.Chtimes(filePath, mtime, mtime))
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:       client,
+				bucketName:   "test-bucket",
+				backupDirs:   []string{dir},
+				fileMetadata: tc.fileMetadata,
+			}
+
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+			require.Len(t, client.putInputs, 1)
+
+			metadata := client.putInputs[0].Metadata
+			if !tc.fileMetadata {
+				assert.Nil(t, metadata)
+				return
+			}
+
+			assert.Equal(t, "2025-06-01T12:30:00Z", metadata[metadataMTime])
+			assert.Equal(t, "600", metadata[metadataMode])
+			assert.Equal(t, "5", metadata[metadataSize])
+			assert.LessOrEqual(t, totalMetadataBytes(metadata), maxMetadataBytes)
+			for key, value := range metadata {
+				assert.Equal(t, strings.ToLower(key), key, "metadata keys must be lowercase")
+				assert.True(t, isASCII(value), "metadata value %q must be ASCII", value)
+			}
+
+			if tc.wantPath {
+				path, err := new(mime.WordDecoder).DecodeHeader(metadata[metadataOriginalPath])
+				require.NoError(t, err)
+				assert.Equal(t, filePath, path)
+			} else {
+				assert.NotContains(t, metadata, metadataOriginalPath)
+			}
+		})
+	}
+}
+
+// isASCII reports whether s only contains ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func TestService_BackupFile_ContentDisposition(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		contentDisposition string
+		fileName           string
+		want               *string
+	}{
+		"disabled": {
+			fileName: "report.pdf",
+		},
+		"attachment": {
+			contentDisposition: "attachment",
+			fileName:           "report.pdf",
+			want:               aws.String("attachment; filename=report.pdf"),
+		},
+		"inline": {
+			contentDisposition: "inline",
+			fileName:           "report.pdf",
+			want:               aws.String("inline; filename=report.pdf"),
+		},
+		"name with spaces is quoted": {
+			contentDisposition: "attachment",
+			fileName:           "Q3 report.pdf",
+			want:               aws.String(`attachment; filename="Q3 report.pdf"`),
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			dir := filepath.Join(root, "finance", "2025", "q3")
+			require.NoError(t, os.MkdirAll(dir, 0750))
+			createFile(t, dir, tc.fileName, "hello")
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:             client,
+				bucketName:         "test-bucket",
+				backupDirs:         []string{root},
+				contentDisposition: tc.contentDisposition,
+			}
+
+			filePath := filepath.Join(dir, tc.fileName)
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+			require.Len(t, client.putInputs, 1)
+
+			input := client.putInputs[0]
+			assert.Equal(t, tc.want, input.ContentDisposition)
+			assert.Contains(t, *input.Key, "finance/2025/q3/", "the key keeps the directory structure")
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// bytesPerMB is the number of bytes in one MiB.
+const bytesPerMB = 1024 * 1024
+
+// partSizeBytes returns the multipart upload part size in bytes.
+func (s *Service) partSizeBytes() int64 {
+	return int64(s.partSizeMB) * bytesPerMB
+}
+
+// useMultipart reports whether a file of the given size should be uploaded in parts.
+// Only files larger than a single part are split.
+func (s *Service) useMultipart(size int64) bool {
+	return s.multipart && size > s.partSizeBytes()
+}
+
+// putMultipart uploads input in parts of the configured part size, uploading up to
+// multipartConcurrency parts at the same time.
+// Whole-object checksums do not apply to multipart uploads, so the configured
+// checksum algorithm is applied to each part by the SDK instead.
+func (s *Service) putMultipart(ctx context.Context, input *s3.PutObjectInput) error {
+	const op = "s3.Service.putMultipart"
+
+	input.ChecksumAlgorithm = checksumAlgorithmType(s.checksumAlgorithm)
+
+	uploader := manager.NewUploader(s.client, func(u *manager.Uploader) {
+		u.PartSize = s.partSizeBytes()
+		// Keep the SDK default, which matches config.DefaultMultipartConcurrency, when unset
+		if s.multipartConcurrency > 0 {
+			u.Concurrency = s.multipartConcurrency
+		}
+	})
+
+	if _, err := uploader.Upload(ctx, input); err != nil {
+		return fmt.Errorf("%s: multipart upload failed: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"s3-backup/internal/config"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BackupFile_Multipart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		multipart     bool
+		partSizeMB    int
+		fileSize      int64
+		wantPartSizes []int64
+		wantPuts      int
+	}{
+		"file larger than part size is split": {
+			multipart:     true,
+			partSizeMB:    5,
+			fileSize:      11 * bytesPerMB,
+			wantPartSizes: []int64{5 * bytesPerMB, 5 * bytesPerMB, 1 * bytesPerMB},
+		},
+		"custom part size": {
+			multipart:     true,
+			partSizeMB:    6,
+			fileSize:      11 * bytesPerMB,
+			wantPartSizes: []int64{6 * bytesPerMB, 5 * bytesPerMB},
+		},
+		"file within part size uses a single PUT": {
+			multipart:  true,
+			partSizeMB: 5,
+			fileSize:   5 * bytesPerMB,
+			wantPuts:   1,
+		},
+		"multipart disabled uses a single PUT": {
+			partSizeMB: 5,
+			fileSize:   11 * bytesPerMB,
+			wantPuts:   1,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			filePath := filepath.Join(dir, "large.bin")
+			require.NoError(t, os.WriteFile(filePath, make([]byte, tc.fileSize), 0600))
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:     client,
+				bucketName: "test-bucket",
+				backupDirs: []string{dir},
+				multipart:  tc.multipart,
+				partSizeMB: tc.partSizeMB,
+			}
+
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+
+			// Parts are uploaded concurrently, so compare them in a stable order
+			slices.Sort(client.partSizes)
+			slices.Sort(tc.wantPartSizes)
+			assert.Equal(t, tc.wantPartSizes, client.partSizes)
+			assert.Len(t, client.putInputs, tc.wantPuts)
+			require.Len(t, client.putKeys, 1)
+			assert.True(t, strings.HasSuffix(client.putKeys[0], filepath.Base(dir)+"/large.bin"))
+		})
+	}
+}
+
+func TestService_BackupFile_MultipartChecksum(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "large.bin")
+	require.NoError(t, os.WriteFile(filePath, make([]byte, 6*bytesPerMB), 0600))
+
+	client := &mockS3Client{}
+	svc := &Service{
+		client:            client,
+		bucketName:        "test-bucket",
+		backupDirs:        []string{dir},
+		checksumAlgorithm: config.ChecksumSHA256,
+		multipart:         true,
+		partSizeMB:        5,
+	}
+
+	require.NoError(t, svc.backupFile(context.Background(), svc.snapshotTarget(), filePath, time.Now()))
+	assert.Len(t, client.partSizes, 2)
+	assert.Empty(t, client.putInputs, "multipart uploads must not send a whole-object checksum PUT")
+}
+
+func TestService_BackupFile_MultipartFails(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "large.bin")
+	require.NoError(t, os.WriteFile(filePath, make([]byte, 6*bytesPerMB), 0600))
+
+	svc := &Service{
+		client:     &mockS3Client{shouldFail: true},
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		multipart:  true,
+		partSizeMB: 5,
+	}
+
+	err := svc.backupFile(context.Background(), svc.snapshotTarget(), filePath, time.Now())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errMockS3Failure)
+}
+
+func TestService_BackupFile_MultipartConcurrency(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		multipartConcurrency int
+	}{
+		"one part at a time":  {multipartConcurrency: 1},
+		"ten parts at a time": {multipartConcurrency: 10},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			filePath := filepath.Join(dir, "large.bin")
+			require.NoError(t, os.WriteFile(filePath, nil, 0600))
+			// A sparse file large enough for more parts than the highest limit
+			require.NoError(t, os.Truncate(filePath, 12*5*bytesPerMB))
+
+			client := &partTrackingClient{mockS3Client: &mockS3Client{}}
+			svc := &Service{
+				client:               client,
+				bucketName:           "test-bucket",
+				backupDirs:           []string{dir},
+				multipart:            true,
+				partSizeMB:           5,
+				multipartConcurrency: tc.multipartConcurrency,
+			}
+
+			require.NoError(t, svc.backupFile(context.Background(), svc.snapshotTarget(), filePath, time.Now()))
+			assert.Len(t, client.partSizes, 12)
+			assert.LessOrEqual(t, client.maxInFlight, tc.multipartConcurrency)
+			if tc.multipartConcurrency > 1 {
+				assert.Greater(t, client.maxInFlight, 1, "parts should be uploaded concurrently")
+			}
+		})
+	}
+}
+
+// partTrackingClient records the highest number of UploadPart calls in flight.
+type partTrackingClient struct {
+	*mockS3Client
+
+	trackMu     sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *partTrackingClient) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	c.trackMu.Lock()
+	c.inFlight++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	c.trackMu.Unlock()
+
+	// Keep the part in flight long enough for others to overlap with it
+	time.Sleep(20 * time.Millisecond)
+
+	c.trackMu.Lock()
+	c.inFlight--
+	c.trackMu.Unlock()
+
+	return c.mockS3Client.UploadPart(ctx, params, optFns...)
+}
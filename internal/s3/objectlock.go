@@ -0,0 +1,18 @@
+package s3
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// applyObjectLock sets the Object Lock mode and retain-until date on input when an
+// Object Lock mode is configured. The retention period starts at upload time.
+func (s *Service) applyObjectLock(input *s3.PutObjectInput) {
+	if s.objectLockMode == "" {
+		return
+	}
+
+	retainUntil := s.now().AddDate(0, 0, s.objectLockRetainDays).UTC()
+	input.ObjectLockMode = types.ObjectLockMode(s.objectLockMode)
+	input.ObjectLockRetainUntilDate = &retainUntil
+}
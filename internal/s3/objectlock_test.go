@@ -0,0 +1,73 @@
+package s3
+
+import (
+	"context"
+	"path/filepath"
+	"s3-backup/internal/config"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BackupFile_ObjectLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		mode       string
+		retainDays int
+		wantMode   types.ObjectLockMode
+	}{
+		"not locked": {},
+		"governance mode": {
+			mode:       config.ObjectLockModeGovernance,
+			retainDays: 30,
+			wantMode:   types.ObjectLockModeGovernance,
+		},
+		"compliance mode": {
+			mode:       config.ObjectLockModeCompliance,
+			retainDays: 365,
+			wantMode:   types.ObjectLockModeCompliance,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "record.txt", "content")
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:               client,
+				bucketName:           "test-bucket",
+				backupDirs:           []string{dir},
+				objectLockMode:       tc.mode,
+				objectLockRetainDays: tc.retainDays,
+			}
+
+			before := time.Now().UTC()
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filepath.Join(dir, "record.txt"), time.Now()))
+			after := time.Now().UTC()
+			require.Len(t, client.putInputs, 1)
+
+			input := client.putInputs[0]
+			assert.Equal(t, tc.wantMode, input.ObjectLockMode)
+			if tc.mode == "" {
+				assert.Nil(t, input.ObjectLockRetainUntilDate)
+				return
+			}
+
+			require.NotNil(t, input.ObjectLockRetainUntilDate)
+			retainUntil := *input.ObjectLockRetainUntilDate
+			assert.Equal(t, time.UTC, retainUntil.Location())
+			assert.False(t, retainUntil.Before(before.AddDate(0, 0, tc.retainDays)))
+			assert.False(t, retainUntil.After(after.AddDate(0, 0, tc.retainDays)))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package s3
+
+import (
+	"log/slog"
+	"s3-backup/internal/config"
+	"s3-backup/internal/metrics"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Option configures optional dependencies of a Service created by NewS3Service.
+type Option func(*Service)
+
+// WithClientOptions applies opts to the S3 client after the options derived from the config,
+// so they can override them.
+func WithClientOptions(opts ...func(*s3.Options)) Option {
+	return func(s *Service) {
+		s.clientOptions = append(s.clientOptions, opts...)
+	}
+}
+
+// WithClient makes the service send its requests to client instead of an S3 client created
+// from the config, in which case WithClientOptions has no effect.
+func WithClient(client API) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithPresignClient makes the service pre-sign uploaded objects with client instead of a
+// pre-sign client created from the S3 client. It has no effect unless pre-signing is enabled.
+func WithPresignClient(client PresignAPI) Option {
+	return func(s *Service) {
+		s.presignClient = client
+	}
+}
+
+// WithConfig makes Service.Config return a copy of cfg instead of the config the service was
+// created from, e.g. to compare it against the expected settings in tests. It does not change
+// the settings the service uses.
+func WithConfig(cfg *config.Config) Option {
+	return func(s *Service) {
+		s.cfg = cfg.Clone()
+	}
+}
+
+// WithMetricsPublisher makes the service publish the metrics of each backup with p instead of
+// the CloudWatch publisher created from the config, which is only created if a CloudWatch
+// metrics namespace is configured.
+func WithMetricsPublisher(p metrics.MetricsPublisher) Option {
+	return func(s *Service) {
+		s.metricsPublisher = p
+	}
+}
+
+// WithLogger makes the service log to l instead of the default logger.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Service) {
+		s.log = l
+	}
+}
+
+// WithVersion sets the s3-backup version recorded by TagBucket, which is "dev" by default.
+func WithVersion(version string) Option {
+	return func(s *Service) {
+		s.version = version
+	}
+}
+
+// WithNowFunc makes the service read the current time from f instead of time.Now,
+// e.g. for backup timestamps and retention cutoffs.
+func WithNowFunc(f func() time.Time) Option {
+	return func(s *Service) {
+		s.nowFunc = f
+	}
+}
+
+// logger returns the logger configured with WithLogger, or the default logger.
+func (s *Service) logger() *slog.Logger {
+	return loggerOrDefault(s.log)
+}
+
+// now returns the current time from the function configured with WithNowFunc, or time.Now.
+func (s *Service) now() time.Time {
+	if s.nowFunc == nil {
+		return time.Now()
+	}
+	return s.nowFunc()
+}
+
+// loggerOrDefault returns l, or the default logger if l is nil.
+func loggerOrDefault(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return slog.Default()
+	}
+	return l
+}
@@ -0,0 +1,192 @@
+package s3
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"s3-backup/internal/metrics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewS3Service_Options(t *testing.T) {
+	t.Parallel()
+
+	cfg := createTestConfig(t, 1, false)
+	createFile(t, cfg.BackupDirs[0], "file.txt", "content")
+
+	logs := &logRecorder{}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+
+	svc, err := NewS3Service(context.Background(), cfg,
+		WithLogger(slog.New(logs)),
+		WithNowFunc(func() time.Time { return now }))
+	require.NoError(t, err)
+
+	client := &mockS3Client{}
+	svc.client = client
+
+	require.NoError(t, svc.Backup(context.Background()))
+
+	require.Len(t, client.putKeys, 1)
+	assert.Equal(t, "2025-01-02T03-04-05/"+filepath.Base(cfg.BackupDirs[0])+"/file.txt", client.putKeys[0])
+
+	record, ok := logs.find("starting backup")
+	require.True(t, ok, "expected the injected logger to receive service logs")
+	assert.Equal(t, "2025-01-02T03-04-05", recordAttrs(record)["timestamp"])
+}
+
+func TestNewS3Service_IndependentServices(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := createTestConfig(t, 1, false)
+	cfg.CronSchedule = "*/5 * * * *"
+
+	first, err := NewS3Service(ctx, cfg)
+	require.NoError(t, err)
+	second, err := NewS3Service(ctx, cfg)
+	require.NoError(t, err)
+
+	firstErr := make(chan error, 1)
+	secondErr := make(chan error, 1)
+	go func() { firstErr <- first.Start(ctx) }()
+	go func() { secondErr <- second.Start(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+
+	assert.True(t, first.Stop())
+	select {
+	case err := <-firstErr:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("first service did not stop in time")
+	}
+
+	select {
+	case err := <-secondErr:
+		t.Fatalf("second service stopped with the first one: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.True(t, second.Stop())
+	select {
+	case err := <-secondErr:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("second service did not stop in time")
+	}
+}
+
+// fakeMetricsPublisher records the metrics it is asked to publish.
+type fakeMetricsPublisher struct {
+	err     error
+	buckets []string
+	metrics []metrics.BackupMetrics
+}
+
+func (f *fakeMetricsPublisher) Publish(_ context.Context, bucket string, m metrics.BackupMetrics) error {
+	f.buckets = append(f.buckets, bucket)
+	f.metrics = append(f.metrics, m)
+	return f.err
+}
+
+func TestNewS3Service_MetricsPublisher(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		namespace     string
+		opts          []Option
+		wantPublisher func(t *testing.T, p metrics.MetricsPublisher)
+	}{
+		"disabled": {
+			wantPublisher: func(t *testing.T, p metrics.MetricsPublisher) {
+				assert.Nil(t, p)
+			},
+		},
+		"CloudWatch namespace": {
+			namespace: "S3Backup/Prod",
+			wantPublisher: func(t *testing.T, p metrics.MetricsPublisher) {
+				assert.IsType(t, &metrics.CloudWatchMetricsPublisher{}, p)
+			},
+		},
+		"option overrides the namespace": {
+			namespace: "S3Backup/Prod",
+			opts:      []Option{WithMetricsPublisher(&fakeMetricsPublisher{})},
+			wantPublisher: func(t *testing.T, p metrics.MetricsPublisher) {
+				assert.IsType(t, &fakeMetricsPublisher{}, p)
+			},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := createTestConfig(t, 1, false)
+			cfg.CloudWatchMetricsNamespace = tc.namespace
+
+			svc, err := NewS3Service(context.Background(), cfg, tc.opts...)
+			require.NoError(t, err)
+			tc.wantPublisher(t, svc.metricsPublisher)
+		})
+	}
+}
+
+func TestService_Backup_PublishesMetrics(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		publishErr error
+		wantWarn   bool
+	}{
+		"published":         {},
+		"publishing failed": {publishErr: errMockS3Failure, wantWarn: true},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "good.txt", "content")
+			createFile(t, dir, "bad.txt", "fails")
+
+			start := time.Date(2025, 12, 15, 14, 30, 0, 0, time.Local)
+			calls := 0
+			logs := &logRecorder{}
+			publisher := &fakeMetricsPublisher{err: tc.publishErr}
+			svc := &Service{
+				client: &mockS3Client{failKey: func(key string) bool {
+					return strings.HasSuffix(key, "bad.txt")
+				}},
+				bucketName:       "test-bucket",
+				backupDirs:       []string{dir},
+				metricsPublisher: publisher,
+				log:              slog.New(logs),
+				// The backup starts at the first call and completes at the second
+				nowFunc: func() time.Time {
+					calls++
+					return start.Add(time.Duration(calls-1) * 2 * time.Second)
+				},
+			}
+
+			// Only the failed file fails the backup, publishing errors are logged
+			require.Error(t, svc.Backup(context.Background()))
+
+			assert.Equal(t, []string{"test-bucket"}, publisher.buckets)
+			assert.Equal(t, []metrics.BackupMetrics{{
+				FilesUploaded: 1,
+				FilesFailed:   1,
+				BytesUploaded: int64(len("content")),
+				Duration:      2 * time.Second,
+			}}, publisher.metrics)
+
+			_, warned := logs.find("failed to publish backup metrics")
+			assert.Equal(t, tc.wantWarn, warned)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// placeholderName is the name of the empty object uploaded into a directory without files.
+const placeholderName = ".keep"
+
+// putPlaceholders uploads an empty placeholderName object into each of dirs, which hold no
+// files, so that restoring the backup recreates them. Failures are returned as BackupError
+// values joined together, like those of files.
+func (s *Service) putPlaceholders(ctx context.Context, target backupTarget, dirs []string, timestamp time.Time) error {
+	var joinedErrs error
+	for _, dir := range dirs {
+		if err := s.putPlaceholder(ctx, target, dir, timestamp); err != nil {
+			joinedErrs = errors.Join(joinedErrs, err)
+		}
+	}
+	return joinedErrs
+}
+
+// putPlaceholder uploads an empty placeholderName object into dir.
+func (s *Service) putPlaceholder(ctx context.Context, target backupTarget, dir string, timestamp time.Time) error {
+	const op = "s3.Service.putPlaceholder"
+
+	placeholder := filepath.Join(dir, placeholderName)
+	s3Key, err := s.buildS3Key(target, placeholder)
+	if err != nil {
+		return &BackupError{FilePath: placeholder, Cause: fmt.Errorf("%s: %w", op, err)}
+	}
+
+	key := buildObjectKey(s3Key, s.keySeparator(), s.prefixLayout(), timestamp)
+	if err := s.checkKeyLength(key); err != nil {
+		return &BackupError{FilePath: placeholder, Cause: fmt.Errorf("%s: %w", op, err)}
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:        &s.bucketName,
+		Key:           &key,
+		Body:          strings.NewReader(""),
+		ContentLength: aws.Int64(0),
+		RequestPayer:  s.requestPayer(),
+	}
+	s.applyEncryption(input)
+	s.applyObjectLock(input)
+
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return &BackupError{FilePath: placeholder, Cause: fmt.Errorf("%s: failed to upload placeholder: %w", op, err)}
+	}
+
+	s.logger().Debug("uploaded empty directory placeholder", "dir", dir, "key", key)
+	return nil
+}
@@ -0,0 +1,136 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Backup_EmptyDirPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2025, 12, 15, 14, 30, 0, 0, time.Local)
+
+	tc := map[string]struct {
+		placeholder bool
+		recursive   bool
+		// dirs and files are created below the backup directory
+		dirs  []string
+		files []string
+		// wantPlaceholders are the directories, relative to the backup directory, that get a .keep object
+		wantPlaceholders []string
+		wantFiles        int
+	}{
+		"empty backup directory": {
+			placeholder:      true,
+			wantPlaceholders: []string{"."},
+		},
+		"backup directory with files": {
+			placeholder: true,
+			files:       []string{"file.txt"},
+			wantFiles:   1,
+		},
+		"disabled": {},
+		"empty subdirectories": {
+			placeholder: true,
+			recursive:   true,
+			dirs:        []string{"empty", "full", "parent/child"},
+			files:       []string{"full/file.txt"},
+			wantFiles:   1,
+			// parent is recreated by the placeholder of its child
+			wantPlaceholders: []string{"empty", "parent/child"},
+		},
+		"subdirectory with skipped files only": {
+			placeholder:      true,
+			recursive:        true,
+			dirs:             []string{"hidden"},
+			files:            []string{"file.txt", "hidden/.env"},
+			wantPlaceholders: []string{"hidden"},
+			wantFiles:        1,
+		},
+		"subdirectories are not entered without recursion": {
+			placeholder: true,
+			dirs:        []string{"empty"},
+			files:       []string{"file.txt"},
+			wantFiles:   1,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for _, sub := range tc.dirs {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0750))
+			}
+			for _, file := range tc.files {
+				createFile(t, dir, file, "content")
+			}
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:              client,
+				bucketName:          "test-bucket",
+				backupDirs:          []string{dir},
+				recursive:           tc.recursive,
+				excludeHidden:       true,
+				emptyDirPlaceholder: tc.placeholder,
+				nowFunc:             func() time.Time { return ts },
+			}
+
+			require.NoError(t, svc.Backup(context.Background()))
+
+			var gotPlaceholders []string
+			for i, key := range client.putKeys {
+				if path.Base(key) == placeholderName {
+					gotPlaceholders = append(gotPlaceholders, key)
+					assert.Equal(t, int64(0), client.putSizes[i])
+					assert.Equal(t, int64(0), aws.ToInt64(client.putInputs[i].ContentLength))
+				}
+			}
+
+			var wantKeys []string
+			for _, sub := range tc.wantPlaceholders {
+				rel := filepath.ToSlash(filepath.Join(filepath.Base(dir), sub, placeholderName))
+				wantKeys = append(wantKeys, buildObjectKey(rel, "/", timestampLayout, ts))
+			}
+			slices.Sort(gotPlaceholders)
+			assert.Equal(t, wantKeys, gotPlaceholders)
+			assert.Len(t, client.putKeys, tc.wantFiles+len(wantKeys))
+		})
+	}
+}
+
+func TestService_Backup_EmptyDirPlaceholderFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	client := &mockS3Client{failKey: func(key string) bool {
+		return strings.HasSuffix(key, "/"+placeholderName)
+	}}
+	svc := &Service{
+		client:              client,
+		bucketName:          "test-bucket",
+		backupDirs:          []string{dir},
+		emptyDirPlaceholder: true,
+	}
+
+	err := svc.Backup(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errMockS3Failure)
+
+	var backupErr *BackupError
+	require.True(t, errors.As(err, &backupErr))
+	assert.Equal(t, filepath.Join(dir, placeholderName), backupErr.FilePath)
+}
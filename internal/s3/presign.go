@@ -0,0 +1,36 @@
+package s3
+
+import (
+	"context"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// PresignAPI is the subset of the S3 pre-sign client used to share uploaded objects, see WithPresignClient.
+type PresignAPI interface {
+	PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// logPresignedURL logs a pre-signed GET URL of the uploaded object key when pre-signing is enabled.
+// Failures are only logged, since the object itself was uploaded.
+func (s *Service) logPresignedURL(ctx context.Context, key string) {
+	if !s.presign {
+		return
+	}
+
+	req, err := s.presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:       &s.bucketName,
+		Key:          &key,
+		RequestPayer: s.requestPayer(),
+	}, s3.WithPresignExpires(s.presignExpiry))
+	if err != nil {
+		s.logger().Warn("failed to pre-sign uploaded object", "key", key, "error", err)
+		return
+	}
+
+	s.logger().Info("pre-signed uploaded object",
+		"key", key,
+		"presigned_url", req.URL,
+		"expires_in", s.presignExpiry.String())
+}
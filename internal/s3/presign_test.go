@@ -0,0 +1,168 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BackupFile_Presign(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+
+	tc := map[string]struct {
+		presign    bool
+		presignErr error
+		uploadErr  bool
+		wantURL    bool
+		wantWarn   bool
+	}{
+		"enabled": {
+			presign: true,
+			wantURL: true,
+		},
+		"disabled": {},
+		"pre-signing fails": {
+			presign:    true,
+			presignErr: errors.New("no credentials"),
+			wantWarn:   true,
+		},
+		"upload fails": {
+			presign:   true,
+			uploadErr: true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "report.pdf", "content")
+
+			logs := &logRecorder{}
+			presigner := &mockPresignClient{err: tc.presignErr}
+			svc := &Service{
+				client:        &mockS3Client{shouldFail: tc.uploadErr},
+				bucketName:    "test-bucket",
+				backupDirs:    []string{dir},
+				presign:       tc.presign,
+				presignExpiry: 6 * time.Hour,
+				presignClient: presigner,
+				log:           slog.New(logs),
+			}
+
+			filePath := filepath.Join(dir, "report.pdf")
+			err := svc.backupFile(ctx, svc.snapshotTarget(), filePath, ts)
+			if tc.uploadErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			s3Key, err := svc.buildS3Key(svc.snapshotTarget(), filePath)
+			require.NoError(t, err)
+			wantKey := buildObjectKey(s3Key, svc.keySeparator(), timestampLayout, ts)
+
+			record, ok := logs.find("pre-signed uploaded object")
+			assert.Equal(t, tc.wantURL, ok)
+			if tc.wantURL {
+				attrs := recordAttrs(record)
+				assert.Equal(t, slog.LevelInfo, record.Level)
+				assert.Equal(t, wantKey, attrs["key"])
+				assert.Equal(t, "https://test-bucket.s3.amazonaws.com/"+wantKey+"?X-Amz-Signature=test", attrs["presigned_url"])
+				assert.Equal(t, "6h0m0s", attrs["expires_in"])
+				assert.Equal(t, []time.Duration{6 * time.Hour}, presigner.expiries)
+			}
+
+			_, warned := logs.find("failed to pre-sign uploaded object")
+			assert.Equal(t, tc.wantWarn, warned)
+
+			if !tc.presign || tc.uploadErr {
+				assert.Empty(t, presigner.keys, "nothing should be pre-signed")
+			}
+		})
+	}
+}
+
+func TestNewS3Service_PresignClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		presign bool
+		opts    []Option
+		want    bool
+	}{
+		"disabled": {},
+		"enabled": {
+			presign: true,
+			want:    true,
+		},
+		"enabled with custom client": {
+			presign: true,
+			opts:    []Option{WithClient(&mockS3Client{})},
+			want:    true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := createTestConfig(t, 1, false)
+			cfg.PresignAfterUpload = tc.presign
+
+			svc, err := NewS3Service(ctx, cfg, tc.opts...)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, svc.presign)
+			assert.Equal(t, tc.want, svc.presignClient != nil)
+			if tc.want {
+				assert.Equal(t, 24*time.Hour, svc.presignExpiry)
+			}
+		})
+	}
+}
+
+// mockPresignClient returns a fake pre-signed URL for the requested key, recording the keys
+// and expiries it was asked for.
+type mockPresignClient struct {
+	err error
+
+	mu       sync.Mutex
+	keys     []string
+	expiries []time.Duration
+}
+
+func (m *mockPresignClient) PresignGetObject(_ context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	var opts s3.PresignOptions
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = append(m.keys, *params.Key)
+	m.expiries = append(m.expiries, opts.Expires)
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &v4.PresignedHTTPRequest{
+		URL:    "https://" + *params.Bucket + ".s3.amazonaws.com/" + *params.Key + "?X-Amz-Signature=test",
+		Method: "GET",
+	}, nil
+}
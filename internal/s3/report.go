@@ -0,0 +1,164 @@
+package s3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Status of a backup in its report.
+const (
+	reportStatusSuccess = "success"
+	reportStatusFailure = "failure"
+	reportStatusPartial = "partial"
+)
+
+// BackupStats summarizes the uploads of a single backup.
+type BackupStats struct {
+	StartedAt     time.Time
+	CompletedAt   time.Time
+	FilesUploaded int
+	FilesFailed   int
+	BytesUploaded int64
+	// S3Keys are the keys of the uploaded objects, in the order their uploads completed
+	S3Keys []string
+}
+
+// backupReport is the JSON document written by WriteReport.
+type backupReport struct {
+	StartedAt     time.Time `json:"started_at"`
+	CompletedAt   time.Time `json:"completed_at"`
+	Status        string    `json:"status"`
+	FilesUploaded int       `json:"files_uploaded"`
+	FilesFailed   int       `json:"files_failed"`
+	BytesUploaded int64     `json:"bytes_uploaded"`
+	Errors        []string  `json:"errors"`
+	S3Keys        []string  `json:"s3_keys"`
+}
+
+// WriteReport writes a JSON report of the backup summarized by stats, which returned err, to path,
+// with the status given by BackupStats.Status. The report is written to a temp file next to path
+// and renamed over it, so readers never see a partial report.
+func WriteReport(path string, stats BackupStats, err error) (writeErr error) {
+	const op = "s3.WriteReport"
+
+	report := backupReport{
+		StartedAt:     stats.StartedAt,
+		CompletedAt:   stats.CompletedAt,
+		Status:        stats.Status(err),
+		FilesUploaded: stats.FilesUploaded,
+		FilesFailed:   stats.FilesFailed,
+		BytesUploaded: stats.BytesUploaded,
+		Errors:        errorMessages(err),
+		S3Keys:        stats.S3Keys,
+	}
+	if report.S3Keys == nil {
+		report.S3Keys = []string{}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("%s: failed to create temp file: %w", op, err)
+	}
+	defer func() {
+		if writeErr != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("%s: failed to encode report: %w", op, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%s: failed to close temp file: %w", op, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("%s: failed to rename temp file: %w", op, err)
+	}
+	return nil
+}
+
+// Status returns the status of the backup summarized by stats, which returned err: success if
+// err is nil, partial if some files were uploaded despite err, and failure otherwise.
+func (stats BackupStats) Status(err error) string {
+	switch {
+	case err == nil:
+		return reportStatusSuccess
+	case stats.FilesUploaded > 0:
+		return reportStatusPartial
+	default:
+		return reportStatusFailure
+	}
+}
+
+// errorMessages returns the messages of the errors joined into err, e.g. one per failed file,
+// or the message of err itself if it joins none.
+func errorMessages(err error) []string {
+	if err == nil {
+		return []string{}
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			var msgs []string
+			for _, inner := range joined.Unwrap() {
+				msgs = append(msgs, inner.Error())
+			}
+			return msgs
+		}
+	}
+	return []string{err.Error()}
+}
+
+// statsRecorder collects the BackupStats of a backup from concurrent uploads.
+// A nil *statsRecorder records nothing, for uploads made outside of a backup.
+type statsRecorder struct {
+	mu    sync.Mutex
+	stats BackupStats
+}
+
+// recordUpload records the upload of size bytes to key.
+func (r *statsRecorder) recordUpload(key string, size int64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.FilesUploaded++
+	r.stats.BytesUploaded += size
+	r.stats.S3Keys = append(r.stats.S3Keys, key)
+}
+
+// recordFailure records a file that could not be backed up.
+func (r *statsRecorder) recordFailure() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.FilesFailed++
+}
+
+// snapshot returns a copy of the stats recorded so far.
+func (r *statsRecorder) snapshot() BackupStats {
+	if r == nil {
+		return BackupStats{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats
+	stats.S3Keys = slices.Clone(r.stats.S3Keys)
+	return stats
+}
@@ -0,0 +1,183 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReport(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+	fileErr := &BackupError{FilePath: "/data/b.txt", Cause: errMockS3Failure}
+
+	tc := map[string]struct {
+		stats      BackupStats
+		err        error
+		wantStatus string
+		wantErrors []string
+	}{
+		"success": {
+			stats:      BackupStats{FilesUploaded: 2, BytesUploaded: 10, S3Keys: []string{"k1", "k2"}},
+			wantStatus: "success",
+			wantErrors: []string{},
+		},
+		"partial": {
+			stats:      BackupStats{FilesUploaded: 1, FilesFailed: 1, BytesUploaded: 4, S3Keys: []string{"k1"}},
+			err:        fmt.Errorf("s3.Service.Backup: one or more files failed to backup: %w", errors.Join(fileErr)),
+			wantStatus: "partial",
+			wantErrors: []string{fileErr.Error()},
+		},
+		"failure": {
+			err:        ErrTooFewFiles,
+			wantStatus: "failure",
+			wantErrors: []string{ErrTooFewFiles.Error()},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "report.json")
+			// An existing report is replaced as a whole
+			require.NoError(t, os.WriteFile(path, []byte("stale"), 0600))
+
+			tc.stats.StartedAt = started
+			tc.stats.CompletedAt = started.Add(3 * time.Second)
+			require.NoError(t, WriteReport(path, tc.stats, tc.err))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			var report map[string]any
+			require.NoError(t, json.Unmarshal(data, &report))
+			keys := tc.stats.S3Keys
+			if keys == nil {
+				keys = []string{}
+			}
+			assert.Equal(t, map[string]any{
+				"started_at":     "2025-12-15T14:30:00Z",
+				"completed_at":   "2025-12-15T14:30:03Z",
+				"status":         tc.wantStatus,
+				"files_uploaded": float64(tc.stats.FilesUploaded),
+				"files_failed":   float64(tc.stats.FilesFailed),
+				"bytes_uploaded": float64(tc.stats.BytesUploaded),
+				"errors":         toAnySlice(tc.wantErrors),
+				"s3_keys":        toAnySlice(keys),
+			}, report)
+
+			leftovers, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+			require.NoError(t, err)
+			assert.Empty(t, leftovers, "the temp file should be renamed")
+		})
+	}
+}
+
+func TestWriteReport_MissingDirectory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "missing", "report.json")
+	require.Error(t, WriteReport(path, BackupStats{}, nil))
+}
+
+func TestService_Backup_WritesReport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "good.txt", "content")
+	createFile(t, dir, "bad.txt", "fails")
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+
+	ts := time.Date(2025, 12, 15, 14, 30, 0, 0, time.Local)
+	svc := &Service{
+		client: &mockS3Client{failKey: func(key string) bool {
+			return strings.HasSuffix(key, "bad.txt")
+		}},
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		reportFile: reportFile,
+		nowFunc:    func() time.Time { return ts },
+	}
+
+	require.Error(t, svc.Backup(context.Background()))
+
+	data, err := os.ReadFile(reportFile)
+	require.NoError(t, err)
+
+	var report backupReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "partial", report.Status)
+	assert.Equal(t, 1, report.FilesUploaded)
+	assert.Equal(t, 1, report.FilesFailed)
+	assert.Equal(t, int64(len("content")), report.BytesUploaded)
+	assert.Equal(t, []string{buildObjectKey(filepath.Base(dir)+"/good.txt", "/", timestampLayout, ts)}, report.S3Keys)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "bad.txt")
+}
+
+func TestService_Backup_ReportFailureDoesNotFailBackup(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+
+	logs := &logRecorder{}
+	client := &mockS3Client{}
+	svc := &Service{
+		client:     client,
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		reportFile: filepath.Join(t.TempDir(), "missing", "report.json"),
+		log:        slog.New(logs),
+	}
+
+	require.NoError(t, svc.Backup(context.Background()))
+	assert.Len(t, client.putKeys, 1)
+	_, warned := logs.find("failed to write backup report")
+	assert.True(t, warned)
+}
+
+func TestService_BackupWithStats(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "good.txt", "content")
+	createFile(t, dir, "bad.txt", "fails")
+
+	svc := &Service{
+		client: &mockS3Client{failKey: func(key string) bool {
+			return strings.HasSuffix(key, "bad.txt")
+		}},
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+	}
+
+	stats, err := svc.BackupWithStats(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 1, stats.FilesUploaded)
+	assert.Equal(t, 1, stats.FilesFailed)
+	assert.Equal(t, int64(len("content")), stats.BytesUploaded)
+	assert.Equal(t, "partial", stats.Status(err))
+}
+
+// toAnySlice converts values to the []any that encoding/json decodes a JSON array of strings to.
+func toAnySlice(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
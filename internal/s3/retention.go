@@ -0,0 +1,150 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// maxDeleteObjects is the maximum number of keys S3 accepts in a single DeleteObjects call.
+const maxDeleteObjects = 1000
+
+// PruneOldBackups deletes all backups whose timestamp prefix is older than the configured
+// retention period. It is a no-op when retention is disabled. Prefixes that are not backup
+// timestamps are left untouched.
+func (s *Service) PruneOldBackups(ctx context.Context) error {
+	const op = "s3.Service.PruneOldBackups"
+
+	if s.retentionDays <= 0 {
+		return nil
+	}
+
+	cutoff := s.now().UTC().AddDate(0, 0, -s.retentionDays)
+
+	prefixes, err := s.listBackupPrefixes(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var keys []string
+	for _, prefix := range prefixes {
+		// Only prune a prefix once the newest backup it can hold is past the cutoff
+		_, last, err := parseBackupPrefix(strings.TrimSuffix(prefix, s.keySeparator()))
+		if err != nil || !last.Before(cutoff) {
+			continue
+		}
+
+		prefixKeys, err := s.listKeys(ctx, prefix)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		keys = append(keys, prefixKeys...)
+	}
+
+	if len(keys) == 0 {
+		s.logger().Info("no backups to prune", "retention_days", s.retentionDays)
+		return nil
+	}
+
+	if err := s.deleteKeys(ctx, keys); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.logger().Info("pruned old backups", "retention_days", s.retentionDays, "objects", len(keys))
+	return nil
+}
+
+// listBackupPrefixes returns the top-level prefixes of the bucket, e.g. "2006-01-02T15-04-05/".
+func (s *Service) listBackupPrefixes(ctx context.Context) ([]string, error) {
+	const op = "s3.Service.listBackupPrefixes"
+
+	var prefixes []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:       &s.bucketName,
+		Delimiter:    aws.String(s.keySeparator()),
+		RequestPayer: s.requestPayer(),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to list objects: %w", op, err)
+		}
+
+		for _, prefix := range page.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(prefix.Prefix))
+		}
+	}
+
+	return prefixes, nil
+}
+
+// listKeys returns the keys of all objects under prefix.
+func (s *Service) listKeys(ctx context.Context, prefix string) ([]string, error) {
+	const op = "s3.Service.listKeys"
+
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:       &s.bucketName,
+		Prefix:       &prefix,
+		RequestPayer: s.requestPayer(),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to list objects (prefix=%s): %w", op, prefix, err)
+		}
+
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+
+	return keys, nil
+}
+
+// deleteKeys deletes the given objects in batches of up to maxDeleteObjects.
+// It continues with the remaining batches if one fails, collecting all errors.
+func (s *Service) deleteKeys(ctx context.Context, keys []string) error {
+	const op = "s3.Service.deleteKeys"
+
+	var joinedErrs error
+	for start := 0; start < len(keys); start += maxDeleteObjects {
+		// Check for context cancellation
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		default:
+		}
+
+		batch := keys[start:min(start+maxDeleteObjects, len(keys))]
+		objects := make([]types.ObjectIdentifier, len(batch))
+		for i, key := range batch {
+			objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+		}
+
+		out, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket:       &s.bucketName,
+			Delete:       &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+			RequestPayer: s.requestPayer(),
+		})
+		if err != nil {
+			joinedErrs = errors.Join(joinedErrs, fmt.Errorf("failed to delete %d objects: %w", len(batch), err))
+			continue
+		}
+
+		for _, deleteErr := range out.Errors {
+			joinedErrs = errors.Join(joinedErrs, fmt.Errorf("failed to delete object %s: %s",
+				aws.ToString(deleteErr.Key), aws.ToString(deleteErr.Message)))
+		}
+	}
+
+	if joinedErrs != nil {
+		return fmt.Errorf("%s: one or more objects failed to delete: %w", op, joinedErrs)
+	}
+	return nil
+}
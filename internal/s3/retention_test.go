@@ -0,0 +1,104 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PruneOldBackups(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Now()
+	old := now.AddDate(0, 0, -40).Format(timestampLayout)
+	older := now.AddDate(0, -6, 0).Format(timestampLayout)
+	recent := now.AddDate(0, 0, -1).Format(timestampLayout)
+	cutoffDay := now.AddDate(0, 0, -30).Format(dateLayout)
+	dayBefore := now.AddDate(0, 0, -31).Format(dateLayout)
+
+	tc := map[string]struct {
+		retentionDays int
+		objects       []string
+		wantDeleted   []string
+	}{
+		"retention disabled keeps everything": {
+			retentionDays: 0,
+			objects:       []string{older + "/docs/a.txt", recent + "/docs/a.txt"},
+		},
+		"deletes backups older than the retention period": {
+			retentionDays: 30,
+			objects: []string{
+				older + "/docs/a.txt",
+				old + "/docs/a.txt",
+				old + "/docs/nested/b.txt",
+				recent + "/docs/a.txt",
+			},
+			wantDeleted: []string{older + "/docs/a.txt", old + "/docs/a.txt", old + "/docs/nested/b.txt"},
+		},
+		"keeps backups within the retention period": {
+			retentionDays: 60,
+			objects:       []string{old + "/docs/a.txt", recent + "/docs/a.txt"},
+		},
+		"keeps date prefixes until their whole day is past the retention period": {
+			retentionDays: 30,
+			objects:       []string{dayBefore + "/docs/a.txt", cutoffDay + "/docs/a.txt", recent + "/docs/a.txt"},
+			wantDeleted:   []string{dayBefore + "/docs/a.txt"},
+		},
+		"ignores prefixes that are not backup timestamps": {
+			retentionDays: 1,
+			objects:       []string{"manual/notes.txt", "readme.txt", older + "/docs/a.txt"},
+			wantDeleted:   []string{older + "/docs/a.txt"},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockS3Client{objects: tc.objects}
+			svc := &Service{client: client, bucketName: "test-bucket", retentionDays: tc.retentionDays}
+
+			require.NoError(t, svc.PruneOldBackups(ctx))
+
+			var deleted []string
+			for _, batch := range client.deleted {
+				deleted = append(deleted, batch...)
+			}
+			assert.ElementsMatch(t, tc.wantDeleted, deleted)
+		})
+	}
+}
+
+func TestService_PruneOldBackups_Batches(t *testing.T) {
+	t.Parallel()
+
+	old := time.Now().AddDate(0, 0, -10).Format(timestampLayout)
+	objects := make([]string, maxDeleteObjects+1)
+	for i := range objects {
+		objects[i] = fmt.Sprintf("%s/docs/file-%04d.txt", old, i)
+	}
+
+	client := &mockS3Client{objects: objects}
+	svc := &Service{client: client, bucketName: "test-bucket", retentionDays: 7}
+
+	require.NoError(t, svc.PruneOldBackups(context.Background()))
+
+	require.Len(t, client.deleted, 2)
+	assert.Len(t, client.deleted[0], maxDeleteObjects)
+	assert.Len(t, client.deleted[1], 1)
+}
+
+func TestService_PruneOldBackups_ListFails(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{client: &mockS3Client{shouldFail: true}, bucketName: "test-bucket", retentionDays: 7}
+
+	err := svc.PruneOldBackups(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errMockS3Failure)
+}
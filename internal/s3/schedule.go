@@ -0,0 +1,288 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"s3-backup/internal/config"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// missedJobCheckInterval is how often the scheduler checks for backups missed while the system was suspended.
+	missedJobCheckInterval = time.Minute
+
+	// missedJobTolerance is how late a scheduled backup may start before it is considered missed.
+	missedJobTolerance = time.Minute
+)
+
+// scheduleParser parses standard five-field cron schedules as well as descriptors
+// such as @daily, @hourly, @weekly, @monthly, and @every 1h30m.
+var scheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// secondScheduleParser parses six-field cron schedules whose first field is the second,
+// as well as the descriptors accepted by scheduleParser.
+var secondScheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseSchedule parses schedule with the parser matching the configured cron precision.
+func (s *Service) parseSchedule(schedule string) (cron.Schedule, error) {
+	if s.cronPrecision == config.CronPrecisionSecond {
+		return secondScheduleParser.Parse(schedule)
+	}
+	return scheduleParser.Parse(schedule)
+}
+
+// UpcomingRuns returns the next n times the configured cron schedule triggers a backup,
+// starting from now. It returns an error if the schedule is missing or invalid. The result
+// is shorter than n if the schedule stops firing, e.g. "0 0 31 2 *" never does.
+func (s *Service) UpcomingRuns(n int) ([]time.Time, error) {
+	const op = "s3.Service.UpcomingRuns"
+
+	sched, err := s.parseSchedule(s.cronSchedule)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid cron schedule %q: %w", op, s.cronSchedule, err)
+	}
+
+	runs := make([]time.Time, 0, max(n, 0))
+	next := s.now()
+	for range n {
+		// Next returns the zero time if the schedule does not fire within five years
+		if next = sched.Next(next); next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+	}
+
+	return runs, nil
+}
+
+// jitterDelay returns a random delay of whole seconds below scheduleJitter, or 0 if jitter is disabled.
+func (s *Service) jitterDelay() time.Duration {
+	seconds := int64(s.scheduleJitter / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+
+	random := rand.Int64N
+	if s.jitterRand != nil {
+		random = s.jitterRand
+	}
+	return time.Duration(random(seconds)) * time.Second
+}
+
+// waitJitter waits for jitterDelay before a scheduled backup, so that services sharing a schedule
+// do not all reach S3 at once. It returns ctx.Err() if ctx is cancelled while waiting, or
+// ErrSchedulerStopped if the scheduler is stopped.
+func (s *Service) waitJitter(ctx context.Context) error {
+	delay := s.jitterDelay()
+	if delay <= 0 {
+		return nil
+	}
+
+	s.logger().Debug("delaying scheduled backup", "jitter", delay.String())
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.stopCh:
+		return ErrSchedulerStopped
+	}
+}
+
+// runScheduledBackup runs a single scheduled backup followed by pruning of old backups, with
+// a job context derived from the scheduler context ctx. It is skipped if ctx is cancelled or
+// maxConcurrentRuns previous scheduled backups are still running.
+func (s *Service) runScheduledBackup(ctx context.Context) {
+	if ctx.Err() != nil {
+		s.logger().Warn("skipping scheduled backup: context cancelled")
+		return
+	}
+
+	slots := s.scheduledRunSlots()
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	default:
+		// Record the skipped run, so checkMissedJob does not report it as missed and start it
+		// anyway once the previous run completes
+		s.setLastRun(s.now())
+		s.logger().Warn("backup skipped: previous run still in progress", "max_concurrent_runs", cap(slots))
+		return
+	}
+
+	s.setLastRun(s.now())
+
+	ctx, cancel := s.jobContext(ctx)
+	defer cancel()
+
+	s.logger().Info("starting scheduled backup", "time", s.now().Format(time.RFC3339))
+	if err := s.Backup(ctx); err != nil {
+		s.logger().Error("scheduled backup failed", "error", err)
+		return
+	}
+	s.logger().Info("scheduled backup completed successfully", "time", s.now().Format(time.RFC3339))
+
+	// Only prune after a successful backup so a failing job never leaves the bucket without recent copies
+	if err := s.PruneOldBackups(ctx); err != nil {
+		s.logger().Error("failed to prune old backups", "error", err)
+	}
+}
+
+// jobContext returns the context of a single scheduled backup started under the scheduler
+// context ctx. With a job timeout the backup is detached from ctx, so stopping the scheduler
+// lets it finish, and is cancelled once the timeout expires instead. Without one it is
+// cancelled together with ctx.
+func (s *Service) jobContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.jobTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), s.jobTimeout)
+}
+
+// scheduledRunSlots returns the semaphore that limits the scheduled backups running at the same
+// time to maxConcurrentRuns, or 1 if it is not set. Every running backup holds one slot.
+func (s *Service) scheduledRunSlots() chan struct{} {
+	s.runSlotsOnce.Do(func() {
+		s.runSlots = make(chan struct{}, max(s.maxConcurrentRuns, 1))
+	})
+	return s.runSlots
+}
+
+// recoverPanics is a cron.JobWrapper that recovers a panic in job, e.g. a nil pointer
+// dereference during a backup, and logs it with its stack trace instead of crashing the
+// process. Each panic is counted, see SchedulerPanics.
+func (s *Service) recoverPanics(job cron.Job) cron.Job {
+	return cron.FuncJob(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				s.logger().Error("scheduled backup panicked",
+					"panic", r,
+					"stack", string(debug.Stack()),
+					"s3backup_scheduler_panics_total", s.schedulerPanics.Add(1))
+			}
+		}()
+		job.Run()
+	})
+}
+
+// SchedulerPanics returns the number of panics recovered from scheduled backups since the
+// service was created. A non-zero value points to a bug worth reporting.
+func (s *Service) SchedulerPanics() uint64 {
+	return s.schedulerPanics.Load()
+}
+
+// runStartupBackup runs the backup requested by config.EnvRunOnStart before the scheduler starts.
+// A failure is only logged, unless stopOnStartupFailure is set, in which case it is returned so
+// that the scheduler is never started.
+func (s *Service) runStartupBackup(ctx context.Context) error {
+	const op = "s3.Service.runStartupBackup"
+
+	ctx, cancel := s.jobContext(ctx)
+	defer cancel()
+
+	s.logger().Info("running backup on start")
+	if err := s.Backup(ctx); err != nil {
+		if s.stopOnStartupFailure {
+			return fmt.Errorf("%s: backup on start failed: %w", op, err)
+		}
+		s.logger().Error("backup on start failed, starting scheduler anyway", "error", err)
+		return nil
+	}
+	s.logger().Info("backup on start completed successfully")
+	return nil
+}
+
+// watchMissedJobs periodically checks whether a scheduled backup was missed until the
+// scheduler is stopped or the context is cancelled.
+func (s *Service) watchMissedJobs(ctx context.Context, schedule cron.Schedule) {
+	ticker := time.NewTicker(missedJobCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.checkMissedJob(ctx, schedule, now)
+		}
+	}
+}
+
+// checkMissedJob reports whether the run expected after the last run should already have
+// started by now, e.g. because the system was suspended during the backup window.
+// A missed job is run immediately under config.CronMissedJobRunImmediately and skipped otherwise.
+func (s *Service) checkMissedJob(ctx context.Context, schedule cron.Schedule, now time.Time) bool {
+	expected := schedule.Next(s.getLastRun())
+	// A run delayed by jitter records its start only after waiting, so it is not missed until then
+	if !now.After(expected.Add(missedJobTolerance + s.scheduleJitter)) {
+		return false
+	}
+
+	s.logger().Warn("scheduled backup was missed",
+		"expected", expected.Format(time.RFC3339),
+		"policy", s.cronMissedJob)
+
+	if s.cronMissedJob == config.CronMissedJobRunImmediately {
+		s.recoverPanics(cron.FuncJob(func() {
+			s.runScheduledBackup(ctx)
+		})).Run()
+		return true
+	}
+
+	// Wait for the next scheduled run without warning about this one again
+	s.setLastRun(now)
+	return true
+}
+
+// getLastRun returns the time of the last scheduled backup, started or skipped.
+// This method is safe to call concurrently.
+func (s *Service) getLastRun() time.Time {
+	s.lastRunMu.Lock()
+	defer s.lastRunMu.Unlock()
+	return s.lastRun
+}
+
+// setLastRun records the start time of the last scheduled backup.
+// This method is safe to call concurrently.
+func (s *Service) setLastRun(t time.Time) {
+	s.lastRunMu.Lock()
+	defer s.lastRunMu.Unlock()
+	s.lastRun = t
+}
+
+// warnLongInterval logs a warning if the next run of schedule after now is further away than
+// cronWarnInterval, which usually means a mistyped schedule such as "0 0 1 1 *" (only on
+// January 1) or "0 0 31 2 *" (never). It reports whether a warning was logged.
+func (s *Service) warnLongInterval(schedule cron.Schedule, now time.Time) bool {
+	if s.cronWarnInterval <= 0 {
+		return false
+	}
+
+	// Next returns the zero time if the schedule does not fire within five years
+	next := schedule.Next(now)
+	if next.IsZero() {
+		s.logger().Warn("cron schedule never triggers a backup", "schedule", s.cronSchedule)
+		return true
+	}
+
+	if until := next.Sub(now); until > s.cronWarnInterval {
+		s.logger().Warn("next scheduled backup is further away than expected",
+			"schedule", s.cronSchedule,
+			"next_run", next.Format(time.RFC3339),
+			"hours_until", int(until.Hours()),
+			"recommendation", "check the schedule or set "+config.EnvCronWarnLongIntervalHours+" to raise the threshold")
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,728 @@
+package s3
+
+import (
+	"context"
+	"log/slog"
+	"s3-backup/internal/config"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CheckMissedJob(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	schedule, err := scheduleParser.Parse("0 2 * * *")
+	require.NoError(t, err)
+
+	lastRun := time.Date(2025, 12, 15, 2, 0, 0, 0, time.Local)
+
+	tc := map[string]struct {
+		policy      string
+		jitter      time.Duration
+		now         time.Time
+		wantMissed  bool
+		wantBackup  bool
+		wantLastRun time.Time
+	}{
+		"before next run": {
+			policy:      config.CronMissedJobRunImmediately,
+			now:         lastRun.Add(12 * time.Hour),
+			wantLastRun: lastRun,
+		},
+		"within tolerance of next run": {
+			policy:      config.CronMissedJobRunImmediately,
+			now:         lastRun.Add(24*time.Hour + 30*time.Second),
+			wantLastRun: lastRun,
+		},
+		"within jitter of next run": {
+			policy:      config.CronMissedJobRunImmediately,
+			jitter:      10 * time.Minute,
+			now:         lastRun.Add(24*time.Hour + 5*time.Minute),
+			wantLastRun: lastRun,
+		},
+		"missed run is skipped": {
+			policy:      config.CronMissedJobSkip,
+			now:         lastRun.Add(30 * time.Hour),
+			wantMissed:  true,
+			wantLastRun: lastRun.Add(30 * time.Hour),
+		},
+		"missed run is skipped without a policy": {
+			now:         lastRun.Add(30 * time.Hour),
+			wantMissed:  true,
+			wantLastRun: lastRun.Add(30 * time.Hour),
+		},
+		"missed run runs immediately": {
+			policy:     config.CronMissedJobRunImmediately,
+			now:        lastRun.Add(30 * time.Hour),
+			wantMissed: true,
+			wantBackup: true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{
+				client:         &mockS3Client{},
+				bucketName:     "test-bucket",
+				backupDirs:     []string{t.TempDir()},
+				cronMissedJob:  tc.policy,
+				scheduleJitter: tc.jitter,
+			}
+			svc.setLastRun(lastRun)
+
+			before := time.Now()
+			missed := svc.checkMissedJob(ctx, schedule, tc.now)
+
+			assert.Equal(t, tc.wantMissed, missed)
+			if tc.wantBackup {
+				// The immediate backup records its own start time
+				assert.False(t, svc.getLastRun().Before(before))
+				return
+			}
+			assert.Equal(t, tc.wantLastRun, svc.getLastRun())
+		})
+	}
+}
+
+func TestService_JitterDelay(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		jitter    time.Duration
+		random    int64
+		want      time.Duration
+		wantLimit int64
+	}{
+		"disabled": {},
+		"random delay": {
+			jitter:    60 * time.Second,
+			random:    42,
+			want:      42 * time.Second,
+			wantLimit: 60,
+		},
+		"below one second": {
+			jitter: 500 * time.Millisecond,
+			random: 1,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotLimit int64
+			svc := &Service{
+				scheduleJitter: tc.jitter,
+				jitterRand: func(n int64) int64 {
+					gotLimit = n
+					return tc.random
+				},
+			}
+
+			assert.Equal(t, tc.want, svc.jitterDelay())
+			assert.Equal(t, tc.wantLimit, gotLimit)
+		})
+	}
+}
+
+func TestService_JitterDelay_Random(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{scheduleJitter: 10 * time.Second}
+	for range 100 {
+		delay := svc.jitterDelay()
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, 10*time.Second)
+		assert.Zero(t, delay%time.Second)
+	}
+}
+
+func TestService_WaitJitter(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		random   int64
+		cancel   bool
+		stop     bool
+		wantWait time.Duration
+		wantErr  error
+	}{
+		"no delay": {},
+		"waits for the delay": {
+			random:   1,
+			wantWait: time.Second,
+		},
+		"cancelled while waiting": {
+			random:  3600,
+			cancel:  true,
+			wantErr: context.Canceled,
+		},
+		"stopped while waiting": {
+			random:  3600,
+			stop:    true,
+			wantErr: ErrSchedulerStopped,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			svc := &Service{
+				scheduleJitter: time.Hour + time.Second,
+				jitterRand:     func(int64) int64 { return tc.random },
+				stopCh:         make(chan struct{}),
+			}
+
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				if tc.cancel {
+					cancel()
+				}
+				if tc.stop {
+					svc.Stop()
+				}
+			}()
+
+			start := time.Now()
+			err := svc.waitJitter(ctx)
+			assert.Less(t, time.Since(start), time.Minute, "waiting should end without the full delay")
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, time.Since(start), tc.wantWait)
+		})
+	}
+}
+
+func TestService_RunScheduledBackup_SkipsOverlappingRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+
+	now := time.Date(2025, 12, 15, 2, 0, 0, 0, time.Local)
+	client := &mockS3Client{}
+	svc := &Service{
+		client:     client,
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		nowFunc:    func() time.Time { return now },
+	}
+
+	svc.scheduledRunSlots() <- struct{}{}
+	svc.runScheduledBackup(context.Background())
+	<-svc.scheduledRunSlots()
+
+	assert.Empty(t, client.putKeys, "an overlapping run must not start")
+	assert.Equal(t, now, svc.getLastRun(), "the skipped run should be recorded")
+}
+
+func TestService_CheckMissedJob_SkippedOverlappingRun(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := scheduleParser.Parse("0 2 * * *")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+
+	firstRun := time.Date(2025, 12, 15, 2, 0, 0, 0, time.Local)
+	var clock atomic.Pointer[time.Time]
+	clock.Store(&firstRun)
+
+	client := &blockingS3Client{
+		mockS3Client: &mockS3Client{},
+		started:      make(chan struct{}, 1),
+		release:      make(chan struct{}),
+	}
+	logs := &logRecorder{}
+	svc := &Service{
+		client:            client,
+		bucketName:        "test-bucket",
+		backupDirs:        []string{dir},
+		maxConcurrentRuns: 1,
+		cronMissedJob:     config.CronMissedJobRunImmediately,
+		log:               slog.New(logs),
+		nowFunc:           func() time.Time { return *clock.Load() },
+	}
+
+	first := make(chan struct{})
+	go func() {
+		defer close(first)
+		svc.runScheduledBackup(context.Background())
+	}()
+	<-client.started
+
+	// The next day's run is skipped while the first one is still uploading
+	nextRun := firstRun.AddDate(0, 0, 1)
+	clock.Store(&nextRun)
+	svc.runScheduledBackup(context.Background())
+
+	// The watcher must not treat the skipped run as missed and start it again
+	assert.False(t, svc.checkMissedJob(context.Background(), schedule, nextRun.Add(5*time.Minute)))
+	_, missed := logs.find("scheduled backup was missed")
+	assert.False(t, missed)
+
+	close(client.release)
+	<-first
+	assert.Len(t, client.putKeys, 1)
+}
+
+func TestService_RunScheduledBackup_MaxConcurrentRuns(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		maxConcurrentRuns int
+		wantSkipped       bool
+	}{
+		"second run skipped by default": {
+			wantSkipped: true,
+		},
+		"second run skipped at one run": {
+			maxConcurrentRuns: 1,
+			wantSkipped:       true,
+		},
+		"second run allowed at two runs": {
+			maxConcurrentRuns: 2,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "file.txt", "content")
+
+			client := &blockingS3Client{
+				mockS3Client: &mockS3Client{},
+				started:      make(chan struct{}, 2),
+				release:      make(chan struct{}),
+			}
+			logs := &logRecorder{}
+			svc := &Service{
+				client:            client,
+				bucketName:        "test-bucket",
+				backupDirs:        []string{dir},
+				maxConcurrentRuns: tc.maxConcurrentRuns,
+				log:               slog.New(logs),
+			}
+
+			first := make(chan struct{})
+			go func() {
+				defer close(first)
+				svc.runScheduledBackup(context.Background())
+			}()
+			<-client.started
+
+			second := make(chan struct{})
+			go func() {
+				defer close(second)
+				svc.runScheduledBackup(context.Background())
+			}()
+			if tc.wantSkipped {
+				// A skipped run returns while the first one is still in progress
+				<-second
+			} else {
+				// The second run starts uploading while the first one is still in progress
+				<-client.started
+			}
+			close(client.release)
+			<-first
+			<-second
+
+			_, skipped := logs.find("backup skipped: previous run still in progress")
+			assert.Equal(t, tc.wantSkipped, skipped)
+			wantPuts := 2
+			if tc.wantSkipped {
+				wantPuts = 1
+			}
+			assert.Len(t, client.putKeys, wantPuts)
+		})
+	}
+}
+
+// blockingS3Client signals started when an upload begins and holds it until release is closed.
+type blockingS3Client struct {
+	*mockS3Client
+
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.started <- struct{}{}
+	<-c.release
+	return c.mockS3Client.PutObject(ctx, params, optFns...)
+}
+
+func TestService_RunScheduledBackup_JobContext(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		jobTimeout time.Duration
+		// wantRunning is how long the job keeps running after the scheduler context is cancelled
+		wantRunning time.Duration
+		wantErr     error
+	}{
+		"cancelled with the scheduler": {
+			wantErr: context.Canceled,
+		},
+		"detached until the job timeout": {
+			jobTimeout:  300 * time.Millisecond,
+			wantRunning: 100 * time.Millisecond,
+			wantErr:     context.DeadlineExceeded,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "file.txt", "content")
+
+			client := &contextS3Client{started: make(chan struct{}, 1), done: make(chan error, 1)}
+			svc := &Service{
+				client:     client,
+				bucketName: "test-bucket",
+				backupDirs: []string{dir},
+				jobTimeout: tc.jobTimeout,
+				log:        slog.New(&logRecorder{}),
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			finished := make(chan struct{})
+			go func() {
+				defer close(finished)
+				svc.runScheduledBackup(ctx)
+			}()
+			<-client.started
+			cancel()
+
+			if tc.wantRunning > 0 {
+				select {
+				case <-finished:
+					t.Fatal("the job was cancelled with the scheduler context")
+				case <-time.After(tc.wantRunning):
+				}
+			}
+
+			select {
+			case err := <-client.done:
+				assert.ErrorIs(t, err, tc.wantErr)
+			case <-time.After(2 * time.Second):
+				t.Fatal("the job context was never cancelled")
+			}
+			<-finished
+		})
+	}
+}
+
+// contextS3Client signals started when an upload begins and holds it until the context of the
+// upload is done, then sends the error of the context to done.
+type contextS3Client struct {
+	mockS3Client
+
+	started chan struct{}
+	done    chan error
+}
+
+func (c *contextS3Client) PutObject(ctx context.Context, _ *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.started <- struct{}{}
+	<-ctx.Done()
+	c.done <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestService_WarnLongInterval(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tc := map[string]struct {
+		schedule  string
+		interval  time.Duration
+		wantWarn  bool
+		wantMsg   string
+		wantHours int64
+	}{
+		"daily schedule": {
+			schedule: "0 2 * * *",
+			interval: 24 * time.Hour,
+		},
+		"once a year": {
+			schedule:  "0 0 1 1 *",
+			interval:  24 * time.Hour,
+			wantWarn:  true,
+			wantMsg:   "next scheduled backup is further away than expected",
+			wantHours: 5124,
+		},
+		"never fires": {
+			schedule: "0 0 31 2 *",
+			interval: 24 * time.Hour,
+			wantWarn: true,
+			wantMsg:  "cron schedule never triggers a backup",
+		},
+		"custom threshold": {
+			schedule: "@weekly",
+			interval: 8 * 24 * time.Hour,
+		},
+		"weekly over default threshold": {
+			schedule:  "@weekly",
+			interval:  24 * time.Hour,
+			wantWarn:  true,
+			wantMsg:   "next scheduled backup is further away than expected",
+			wantHours: 156,
+		},
+		"disabled": {
+			schedule: "0 0 1 1 *",
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sched, err := scheduleParser.Parse(tc.schedule)
+			require.NoError(t, err)
+
+			logs := &logRecorder{}
+			svc := &Service{cronSchedule: tc.schedule, cronWarnInterval: tc.interval, log: slog.New(logs)}
+
+			assert.Equal(t, tc.wantWarn, svc.warnLongInterval(sched, now))
+			if !tc.wantWarn {
+				assert.Empty(t, logs.records)
+				return
+			}
+
+			record, ok := logs.find(tc.wantMsg)
+			require.True(t, ok, "expected warning %q", tc.wantMsg)
+			assert.Equal(t, slog.LevelWarn, record.Level)
+			if tc.wantHours > 0 {
+				assert.Equal(t, tc.wantHours, recordAttrs(record)["hours_until"])
+			}
+		})
+	}
+}
+
+func TestService_UpcomingRuns(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 3, 30, 0, time.UTC)
+
+	tc := map[string]struct {
+		schedule  string
+		precision string
+		n         int
+		want      []time.Time
+		wantErr   bool
+	}{
+		"every five minutes": {
+			schedule: "*/5 * * * *",
+			n:        3,
+			want: []time.Time{
+				time.Date(2025, 6, 1, 12, 5, 0, 0, time.UTC),
+				time.Date(2025, 6, 1, 12, 10, 0, 0, time.UTC),
+				time.Date(2025, 6, 1, 12, 15, 0, 0, time.UTC),
+			},
+		},
+		"descriptor": {
+			schedule: "@daily",
+			n:        2,
+			want: []time.Time{
+				time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"every fifteen seconds": {
+			schedule:  "*/15 * * * * *",
+			precision: config.CronPrecisionSecond,
+			n:         3,
+			want: []time.Time{
+				time.Date(2025, 6, 1, 12, 3, 45, 0, time.UTC),
+				time.Date(2025, 6, 1, 12, 4, 0, 0, time.UTC),
+				time.Date(2025, 6, 1, 12, 4, 15, 0, time.UTC),
+			},
+		},
+		"descriptor with second precision": {
+			schedule:  "@hourly",
+			precision: config.CronPrecisionSecond,
+			n:         1,
+			want: []time.Time{
+				time.Date(2025, 6, 1, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		"six fields with minute precision": {
+			schedule: "*/15 * * * * *",
+			n:        1,
+			wantErr:  true,
+		},
+		"five fields with second precision": {
+			schedule:  "*/5 * * * *",
+			precision: config.CronPrecisionSecond,
+			n:         1,
+			wantErr:   true,
+		},
+		"never fires": {
+			schedule: "0 0 31 2 *",
+			n:        5,
+			want:     []time.Time{},
+		},
+		"zero runs": {
+			schedule: "*/5 * * * *",
+			want:     []time.Time{},
+		},
+		"invalid schedule": {
+			schedule: "not a schedule",
+			n:        1,
+			wantErr:  true,
+		},
+		"no schedule": {
+			n:       1,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{cronSchedule: tc.schedule, cronPrecision: tc.precision, nowFunc: func() time.Time { return now }}
+
+			runs, err := svc.UpcomingRuns(tc.n)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, runs)
+		})
+	}
+}
+
+func TestService_RecoverPanics(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+
+	logs := &logRecorder{}
+	svc := &Service{
+		client:     &panickingS3Client{},
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		log:        slog.New(logs),
+	}
+
+	job := svc.recoverPanics(cron.FuncJob(func() {
+		svc.runScheduledBackup(context.Background())
+	}))
+	require.NotPanics(t, job.Run)
+	require.NotPanics(t, job.Run)
+
+	assert.Equal(t, uint64(2), svc.SchedulerPanics())
+	assert.Empty(t, svc.scheduledRunSlots(), "a panicking backup must release its run slot")
+
+	record, ok := logs.find("scheduled backup panicked")
+	require.True(t, ok, "expected the panic to be logged")
+	assert.Equal(t, slog.LevelError, record.Level)
+	attrs := recordAttrs(record)
+	assert.Equal(t, "mock S3 panic", attrs["panic"])
+	assert.Contains(t, attrs["stack"], "runScheduledBackup")
+}
+
+// panickingS3Client is an S3 client whose uploads panic, simulating a bug in the backup.
+type panickingS3Client struct {
+	mockS3Client
+}
+
+func (*panickingS3Client) PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	panic("mock S3 panic")
+}
+
+func TestService_Start_RunOnStart(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		shouldFail           bool
+		stopOnStartupFailure bool
+		wantErr              bool
+	}{
+		"successful backup starts the scheduler": {},
+		"failed backup still starts the scheduler": {
+			shouldFail: true,
+		},
+		"failed backup keeps the scheduler from starting when disabled on failure": {
+			shouldFail:           true,
+			stopOnStartupFailure: true,
+			wantErr:              true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "file.txt", "content")
+
+			client := &mockS3Client{shouldFail: tc.shouldFail}
+			svc := &Service{
+				client:               client,
+				bucketName:           "test-bucket",
+				backupDirs:           []string{dir},
+				cronSchedule:         "0 0 * * *",
+				runOnStart:           true,
+				stopOnStartupFailure: tc.stopOnStartupFailure,
+				stopCh:               make(chan struct{}),
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- svc.Start(context.Background())
+			}()
+
+			select {
+			case err := <-errCh:
+				require.True(t, tc.wantErr, "Start() returned unexpectedly: %v", err)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errMockS3Failure)
+				assert.True(t, svc.getLastRun().IsZero(), "the scheduler must not start")
+				return
+			case <-time.After(100 * time.Millisecond):
+				require.False(t, tc.wantErr, "expected Start() to return an error")
+			}
+
+			assert.False(t, svc.getLastRun().IsZero(), "the scheduler should be running")
+			if !tc.shouldFail {
+				assert.Len(t, client.putKeys, 1, "the backup should run before the first scheduled run")
+			}
+
+			assert.True(t, svc.Stop())
+			select {
+			case err := <-errCh:
+				require.NoError(t, err)
+			case <-time.After(2 * time.Second):
+				t.Error("Start() did not stop in time")
+			}
+		})
+	}
+}
@@ -2,42 +2,232 @@ package s3
 
 import (
 	"context"
+	"crypto/md5" //nolint:gosec // G501: MD5 identifies file contents, it is not used for security
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"s3-backup/internal/cache"
 	"s3-backup/internal/config"
+	"s3-backup/internal/metrics"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/robfig/cron/v3"
 )
 
+var _ io.Closer = (*Service)(nil)
+
 // API defines the interface for S3 operations needed by Service.
 type API interface {
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+	PutBucketIntelligentTieringConfiguration(ctx context.Context, params *s3.PutBucketIntelligentTieringConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketIntelligentTieringConfigurationOutput, error)
+	GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+	PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error)
+
+	// Multipart upload operations used by the upload manager
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
 }
 
 // Service wraps the AWS S3 client and provides backup functionality.
-// The client, bucketName, backupDirs, recursive, and cronSchedule fields
-// are immutable after NewS3Service returns.
+// Configuration fields are immutable after NewS3Service returns, except for backupDirs,
+// recursive, and cfg, which ReloadConfig replaces under configMu and each run reads once
+// through snapshotTarget. The cache and the scheduler state are modified under their own locks.
 type Service struct {
 	client       API
 	bucketName   string
-	backupDirs   []string
-	recursive    bool
 	cronSchedule string
+	archiveMode  string
+	// tempDir is where temporary files such as archives are created; "" uses os.TempDir()
+	tempDir string
+
+	// configMu guards the settings that ReloadConfig can change
+	configMu   sync.RWMutex
+	backupDirs []string
+	recursive  bool
+	// cfg is the effective config returned by Config; nil for services not created by NewS3Service
+	cfg *config.Config
+
+	// cronMissedJob is the policy for scheduled backups missed while the system was suspended
+	cronMissedJob string
+	// cronPrecision decides whether the cron schedule has a leading seconds field
+	cronPrecision string
+
+	// scheduleJitter is the longest random delay before a scheduled backup; jitterRand returns a
+	// random number in [0, n) and replaces rand.Int64N when set
+	scheduleJitter time.Duration
+	jitterRand     func(n int64) int64
+
+	// jobTimeout is how long a scheduled backup may run, detached from the scheduler context;
+	// 0 runs it until the scheduler context is cancelled
+	jobTimeout time.Duration
+
+	// cronWarnInterval is how far away the next scheduled backup may be before Start warns; 0 disables the warning
+	cronWarnInterval time.Duration
+
+	// runOnStart runs a backup before the first scheduled run; stopOnStartupFailure makes Start
+	// return instead of starting the scheduler if that backup fails
+	runOnStart           bool
+	stopOnStartupFailure bool
+
+	// preserveAbsolutePath keys objects by their full absolute path instead of their backup directory
+	preserveAbsolutePath bool
+
+	// skipLockedFiles leaves out files another process holds an exclusive lock on
+	skipLockedFiles bool
+
+	// excludeHidden leaves out dotfiles and dot-directories
+	excludeHidden bool
+
+	// maxWalkDepth is how many directory levels below a backup directory are collected; 0 means no limit
+	maxWalkDepth int
+
+	// skipUnchangedDirs skips directories modified before their last successful backup, which
+	// lastBackupTime records by absolute path
+	skipUnchangedDirs bool
+	lastBackupMu      sync.Mutex
+	lastBackupTime    map[string]time.Time
+
+	// emptyDirPlaceholder uploads a placeholderName object into every collected directory without files
+	emptyDirPlaceholder bool
+
+	// minFiles and minBytes are the minimum size of a backup; 0 disables the check
+	minFiles int
+	minBytes int64
+
+	// maxErrors aborts a backup after that many consecutive upload failures; 0 disables the limit
+	maxErrors int
+
+	// progressEvery logs the progress of a backup after every progressEvery successful uploads
+	progressEvery int
+
+	// concurrency limits the files uploaded at the same time, concurrencyPerDir those of a single backup directory
+	concurrency       int
+	concurrencyPerDir int
+
+	// checksumAlgorithm is the integrity checksum sent with each upload; empty disables it
+	checksumAlgorithm string
+
+	// kmsKeyID encrypts uploads with SSE-KMS when set; kmsContext is the encoded encryption context
+	kmsKeyID   string
+	kmsContext string
+	// storageClassMap is the storage class of the objects of each lower-cased file extension
+	storageClassMap map[string]string
+
+	// objectLockMode locks uploads for objectLockRetainDays days when set
+	objectLockMode       string
+	objectLockRetainDays int
+
+	// fileMetadata attaches the original path, modification time, mode, and size of files to their objects
+	fileMetadata bool
+
+	// maxKeyLength is the longest object key in bytes; 0 uses config.MaxObjectKeyLength
+	maxKeyLength int
+
+	// objectKeySeparator replaces "/" in object keys; empty uses config.DefaultObjectKeySeparator
+	objectKeySeparator string
+
+	// prefixDateOnly formats the prefix of object keys with dateLayout instead of timestampLayout
+	prefixDateOnly bool
+
+	// contentDisposition is the Content-Disposition type, e.g. attachment, sent with the file name when set
+	contentDisposition string
+
+	// presign logs a pre-signed GET URL valid for presignExpiry after every upload, created by presignClient
+	presign       bool
+	presignExpiry time.Duration
+	presignClient PresignAPI
+
+	// requesterPays acknowledges requester-pays billing on uploads, listings, and deletes
+	requesterPays bool
+
+	// multipart uploads files larger than partSizeMB in parts of partSizeMB MiB, multipartConcurrency at a time
+	multipart            bool
+	partSizeMB           int
+	multipartConcurrency int
+
+	costPerPutUSD float64
+
+	// cache records previously uploaded files when incremental backups are enabled; nil otherwise
+	cache *cache.Cache
+
+	// reportFile is where a JSON report is written after each backup; "" writes none
+	reportFile string
+
+	// metricsPublisher publishes the metrics of each backup; nil publishes none
+	metricsPublisher metrics.MetricsPublisher
+
+	// updateLatest makes every successful backup overwrite the object at latestPointerKey with its timestamp
+	updateLatest bool
+
+	// retentionDays is how long backups are kept before PruneOldBackups deletes them; 0 keeps them forever
+	retentionDays int
+
+	preflightCheck    bool
+	versioningCheck   bool
+	requireVersioning bool
+
+	// configureTiering makes ConfigureBucket set the Intelligent-Tiering archive tiers of the bucket
+	configureTiering       bool
+	tieringArchiveDays     int
+	tieringDeepArchiveDays int
+
+	// tagBucket makes TagBucket tag the bucket as managed by s3-backup; version is the version it tags
+	tagBucket bool
+	version   string
+
+	// schedulerPanics counts the panics recovered from scheduled backups
+	schedulerPanics atomic.Uint64
+
+	// runSlots holds a value for every running scheduled backup, at most maxConcurrentRuns, see
+	// scheduledRunSlots; lastRun is the time of the last scheduled run, including skipped ones
+	runSlotsOnce      sync.Once
+	runSlots          chan struct{}
+	maxConcurrentRuns int
+	lastRunMu         sync.Mutex
+	lastRun           time.Time
 
 	stopCh   chan struct{}
 	stopOnce sync.Once
+
+	// closeOnce makes Close idempotent; closeErr is the result of the first call
+	closeOnce sync.Once
+	closeErr  error
+
+	// clientOptions are the caller's S3 client options, only used by NewS3Service if WithClient is not given
+	clientOptions []func(*s3.Options)
+
+	// log and nowFunc replace the default logger and time.Now when set
+	log     *slog.Logger
+	nowFunc func() time.Time
 }
 
-// NewS3Service creates a new Service with the provided Config and optional client options.
+// NewS3Service creates a new Service with the provided Config and options.
 // It validates that all backup directories exist and are accessible.
-func NewS3Service(ctx context.Context, cfg *config.Config, opts ...func(*s3.Options)) (*Service, error) {
+// Services are independent of each other, even when created from the same Config.
+// When the preflight check is enabled it also verifies that the bucket is accessible,
+// so it may block on network I/O until ctx is done.
+func NewS3Service(ctx context.Context, cfg *config.Config, opts ...Option) (*Service, error) {
 	const op = "s3.NewS3Service"
 
 	if cfg == nil {
@@ -49,24 +239,160 @@ func NewS3Service(ctx context.Context, cfg *config.Config, opts ...func(*s3.Opti
 		return nil, fmt.Errorf("%s: failed to get AWS config: %w", op, err)
 	}
 
-	s3Client := s3.NewFromConfig(awsCfg, opts...)
-
 	backupDirs := cfg.GetBackupDirs()
 	if err := validateDirectories(backupDirs); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Service{
-		client:       s3Client,
+	var fileCache *cache.Cache
+	if cfg.IsIncremental() {
+		fileCache, err = cache.Load(cfg.GetCacheFile())
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to load incremental backup cache: %w", op, err)
+		}
+	}
+
+	svc := &Service{
+		cfg:          cfg.Clone(),
 		bucketName:   cfg.GetS3Bucket(),
 		backupDirs:   backupDirs,
 		recursive:    cfg.IsRecursive(),
 		cronSchedule: cfg.GetCronSchedule(),
-		stopCh:       make(chan struct{}),
-	}, nil
+		archiveMode:  cfg.GetArchiveMode(),
+		tempDir:      cfg.GetTempDir(),
+
+		cronMissedJob:        cfg.GetCronMissedJob(),
+		cronPrecision:        cfg.GetCronPrecision(),
+		cronWarnInterval:     time.Duration(cfg.GetCronWarnLongIntervalHours()) * time.Hour,
+		scheduleJitter:       time.Duration(cfg.GetScheduleJitterSeconds()) * time.Second,
+		maxConcurrentRuns:    cfg.GetCronMaxConcurrentRuns(),
+		jobTimeout:           time.Duration(cfg.GetJobTimeoutSeconds()) * time.Second,
+		runOnStart:           cfg.IsRunOnStart(),
+		stopOnStartupFailure: cfg.IsSchedulerDisabledOnStartupFailure(),
+		preserveAbsolutePath: cfg.IsPreserveAbsolutePath(),
+		skipLockedFiles:      cfg.IsSkipLockedFiles(),
+		excludeHidden:        cfg.IsExcludeHidden(),
+		skipUnchangedDirs:    cfg.IsSkipUnchangedDirs(),
+		maxWalkDepth:         cfg.GetMaxWalkDepth(),
+		emptyDirPlaceholder:  cfg.IsEmptyDirPlaceholder(),
+
+		minFiles: cfg.GetRequireMinFiles(),
+		minBytes: cfg.GetRequireMinBytes(),
+
+		maxErrors:     cfg.GetMaxErrors(),
+		progressEvery: cfg.GetProgressLogEveryNFiles(),
+
+		concurrency:       cfg.GetConcurrency(),
+		concurrencyPerDir: cfg.GetConcurrencyPerDir(),
+
+		checksumAlgorithm:    cfg.GetChecksumAlgorithm(),
+		kmsKeyID:             cfg.GetKMSKeyID(),
+		kmsContext:           encodeKMSContext(cfg.GetKMSContext()),
+		storageClassMap:      cfg.GetStorageClassMap(),
+		objectLockMode:       cfg.GetObjectLockMode(),
+		objectLockRetainDays: cfg.GetObjectLockRetainDays(),
+		requesterPays:        cfg.IsRequesterPays(),
+		fileMetadata:         cfg.IsFileMetadataEnabled(),
+		contentDisposition:   cfg.GetContentDisposition(),
+		maxKeyLength:         cfg.GetMaxObjectKeyLength(),
+		objectKeySeparator:   cfg.GetObjectKeySeparator(),
+		prefixDateOnly:       cfg.IsObjectPrefixDateOnly(),
+		presign:              cfg.IsPresignAfterUpload(),
+		presignExpiry:        time.Duration(cfg.GetPresignExpiryHours()) * time.Hour,
+		multipart:            cfg.IsMultipartUpload(),
+		partSizeMB:           cfg.GetUploadPartSizeMB(),
+		multipartConcurrency: cfg.GetMultipartConcurrency(),
+
+		costPerPutUSD: cfg.GetCostPerPutUSD(),
+		cache:         fileCache,
+		retentionDays: cfg.GetRetentionDays(),
+		reportFile:    cfg.GetReportFile(),
+		updateLatest:  cfg.IsUpdateLatestPointer(),
+
+		preflightCheck:    cfg.IsPreflightCheckEnabled(),
+		versioningCheck:   cfg.IsVersioningCheckEnabled(),
+		requireVersioning: cfg.IsVersioningRequired(),
+
+		configureTiering:       cfg.IsConfigureIntelligentTiering(),
+		tieringArchiveDays:     cfg.GetIntelligentTieringArchiveAccessDays(),
+		tieringDeepArchiveDays: cfg.GetIntelligentTieringDeepArchiveAccessDays(),
+
+		tagBucket: cfg.IsTagBucket(),
+
+		stopCh: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	if svc.client == nil {
+		// Caller options are applied last so they can override the configured ones
+		svc.client = s3.NewFromConfig(awsCfg, append(clientOptions(cfg), svc.clientOptions...)...)
+	}
+
+	if namespace := cfg.GetCloudWatchMetricsNamespace(); namespace != "" && svc.metricsPublisher == nil {
+		publisher, err := metrics.NewCloudWatchMetricsPublisher(cloudwatch.NewFromConfig(awsCfg), namespace)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		svc.metricsPublisher = publisher
+	}
+
+	if svc.presign && svc.presignClient == nil {
+		// A client passed with WithClient may not be able to sign, so sign with one created from the config
+		client, ok := svc.client.(*s3.Client)
+		if !ok {
+			client = s3.NewFromConfig(awsCfg, append(clientOptions(cfg), svc.clientOptions...)...)
+		}
+		svc.presignClient = s3.NewPresignClient(client)
+	}
+
+	if svc.preflightCheck {
+		if err := svc.CheckBucketAccess(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return svc, nil
+}
+
+// clientOptions returns the S3 client options derived from cfg.
+func clientOptions(cfg *config.Config) []func(*s3.Options) {
+	var opts []func(*s3.Options)
+
+	if userAgent := cfg.GetUserAgent(); userAgent != "" {
+		// Keep "name/version" intact; a bare key would have its slash replaced by the SDK
+		addUserAgent := awsmiddleware.AddUserAgentKey(userAgent)
+		if name, version, ok := strings.Cut(userAgent, "/"); ok {
+			addUserAgent = awsmiddleware.AddUserAgentKeyValue(name, version)
+		}
+
+		opts = append(opts, func(o *s3.Options) {
+			o.APIOptions = append(o.APIOptions, addUserAgent)
+		})
+	}
+
+	// S3-compatible stores such as MinIO or Ceph may expect a signing region that differs from the bucket region
+	if endpointRegion := cfg.GetS3EndpointRegion(); endpointRegion != "" {
+		opts = append(opts, s3.WithSigV4SigningRegion(endpointRegion))
+	}
+
+	if cfg.IsPathStyle() {
+		opts = append(opts, func(o *s3.Options) {
+			o.UsePathStyle = true
+		})
+	}
+
+	if cfg.IsTransferAccelerationEnabled() {
+		opts = append(opts, func(o *s3.Options) {
+			o.UseAccelerate = true
+		})
+	}
+
+	return opts
 }
 
-// validateDirectories ensures all provided directories exist and are accessible.
+// validateDirectories ensures all provided directories exist and can be read by the current user.
 func validateDirectories(dirs []string) error {
 	const op = "s3.validateDirectories"
 	for _, dir := range dirs {
@@ -74,7 +400,12 @@ func validateDirectories(dirs []string) error {
 			return ErrEmptyDirectory
 		}
 
-		info, err := os.Stat(dir)
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("%s: failed to resolve absolute path of %s: %w", op, dir, err)
+		}
+
+		info, err := os.Stat(absDir)
 		if err != nil {
 			if os.IsNotExist(err) {
 				return fmt.Errorf("%s: %w: %s", op, ErrDirectoryNotFound, dir)
@@ -85,6 +416,16 @@ func validateDirectories(dirs []string) error {
 		if !info.IsDir() {
 			return fmt.Errorf("%s: %w: %s", op, ErrNotADirectory, dir)
 		}
+
+		// Stat succeeds without read permission, so list a single entry to catch it before the first backup
+		f, err := os.Open(absDir) //nolint:gosec // G304: dir is a configured backup directory
+		if err == nil {
+			_, err = f.Readdirnames(1)
+			_ = f.Close()
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w: %s: %w", op, ErrDirectoryNotReadable, dir, err)
+		}
 	}
 	return nil
 }
@@ -92,6 +433,9 @@ func validateDirectories(dirs []string) error {
 // getBackupDirs returns a copy of the configured backup directories.
 // This method is safe to call concurrently.
 func (s *Service) getBackupDirs() []string {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
 	dirs := make([]string, len(s.backupDirs))
 	copy(dirs, s.backupDirs)
 	return dirs
@@ -100,43 +444,343 @@ func (s *Service) getBackupDirs() []string {
 // isRecursive returns whether recursive backup is enabled.
 // This method is safe to call concurrently.
 func (s *Service) isRecursive() bool {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
 	return s.recursive
 }
 
+// backupTarget is the set of directories a single backup or estimate reads. It is taken
+// once when the run starts, so a concurrent ReloadConfig never changes it mid-run.
+type backupTarget struct {
+	dirs      []string
+	recursive bool
+	// stats records the uploads of a backup; nil for estimates and single uploads
+	stats *statsRecorder
+}
+
+// snapshotTarget returns the current backup directories and recursive mode.
+// This method is safe to call concurrently.
+func (s *Service) snapshotTarget() backupTarget {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	return backupTarget{dirs: slices.Clone(s.backupDirs), recursive: s.recursive}
+}
+
+// ReloadConfig applies the backup directories and recursive mode of cfg to the service.
+// Other settings, such as the bucket or cron schedule, only take effect after a restart.
+// The directories are validated first; on error the current settings are kept.
+func (s *Service) ReloadConfig(cfg *config.Config) error {
+	const op = "s3.Service.ReloadConfig"
+
+	if cfg == nil {
+		return fmt.Errorf("%s: %w", op, ErrNilConfig)
+	}
+
+	dirs := cfg.GetBackupDirs()
+	if err := validateDirectories(dirs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	s.backupDirs = dirs
+	s.recursive = cfg.IsRecursive()
+	if s.cfg != nil {
+		s.cfg.BackupDirs = slices.Clone(dirs)
+		s.cfg.Recursive = cfg.IsRecursive()
+	}
+	return nil
+}
+
+// Config returns a copy of the effective configuration of the service: the config it was
+// created from, or the one set with WithConfig, with the backup directories and recursive
+// mode of the last ReloadConfig. It returns nil for a service not created by NewS3Service.
+// This method is safe to call concurrently.
+func (s *Service) Config() *config.Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	return s.cfg.Clone()
+}
+
+// BucketVersioningStatus returns the versioning status of the configured bucket.
+// An empty string means versioning has never been enabled on the bucket.
+func (s *Service) BucketVersioningStatus(ctx context.Context) (string, error) {
+	const op = "s3.Service.BucketVersioningStatus"
+
+	out, err := s.client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: &s.bucketName,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get bucket versioning: %w", op, err)
+	}
+
+	return string(out.Status), nil
+}
+
+// CheckBucketAccess verifies that the configured bucket exists and the credentials may access it,
+// so that a misconfiguration fails on startup instead of on the first scheduled backup.
+func (s *Service) CheckBucketAccess(ctx context.Context) error {
+	const op = "s3.Service.CheckBucketAccess"
+
+	if _, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.bucketName)}); err != nil {
+		return fmt.Errorf("%s: %w (bucket=%s): %w", op, ErrBucketNotAccessible, s.bucketName, err)
+	}
+
+	s.logger().Info("bucket is accessible", "bucket", s.bucketName)
+	return nil
+}
+
+// CheckBucketVersioning verifies that versioning is enabled on the configured bucket.
+// It is a no-op unless the versioning check is enabled. When versioning is not enabled
+// it logs a warning, or returns ErrVersioningNotEnabled if versioning is required.
+func (s *Service) CheckBucketVersioning(ctx context.Context) error {
+	const op = "s3.Service.CheckBucketVersioning"
+
+	if !s.versioningCheck {
+		return nil
+	}
+
+	status, err := s.BucketVersioningStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if status == string(types.BucketVersioningStatusEnabled) {
+		s.logger().Info("bucket versioning is enabled", "bucket", s.bucketName)
+		return nil
+	}
+
+	if s.requireVersioning {
+		return fmt.Errorf("%s: %w (bucket=%s, status=%q)", op, ErrVersioningNotEnabled, s.bucketName, status)
+	}
+
+	s.logger().Warn("bucket versioning is not enabled; objects overwritten by later backups cannot be recovered",
+		"bucket", s.bucketName,
+		"status", status,
+		"recommendation", "enable versioning on the bucket or set "+config.EnvRequireVersioning+"=true to enforce it")
+	return nil
+}
+
 // Backup performs the backup of files from the configured directories to the S3 bucket.
 // It respects context cancellation and returns all errors encountered during the backup.
 func (s *Service) Backup(ctx context.Context) error {
 	const op = "s3.Service.Backup"
 
+	if _, err := s.BackupWithStats(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// BackupWithStats performs the backup like Backup and also returns the summary of its uploads.
+// The stats are returned even if the backup fails, e.g. for the files uploaded before it failed.
+func (s *Service) BackupWithStats(ctx context.Context) (BackupStats, error) {
+	return s.backup(ctx, s.snapshotTarget())
+}
+
+// BackupDir backs up dir, which must be one of the configured backup directories, the way
+// Backup does for all of them. It returns ErrDirectoryNotConfigured for any other directory.
+func (s *Service) BackupDir(ctx context.Context, dir string) error {
+	const op = "s3.Service.BackupDir"
+
+	if dir == "" {
+		return fmt.Errorf("%s: %w", op, ErrEmptyDirectory)
+	}
+
+	target := s.snapshotTarget()
+	i := slices.IndexFunc(target.dirs, func(configured string) bool {
+		return sameDirectory(configured, dir)
+	})
+	if i < 0 {
+		return fmt.Errorf("%s: %w: %s", op, ErrDirectoryNotConfigured, dir)
+	}
+	// Keep the configured form of the path, so object keys match those of Backup
+	target.dirs = target.dirs[i : i+1]
+
+	if err := validateDirectories(target.dirs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := s.backup(ctx, target); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// sameDirectory reports whether a and b name the same directory once made absolute.
+func sameDirectory(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// backup uploads the files of the directories in target under a new timestamp prefix and
+// returns the stats of the uploads, writing them to the report file if one is configured.
+func (s *Service) backup(ctx context.Context, target backupTarget) (BackupStats, error) {
 	// Generate a single timestamp for this entire backup operation
-	backupTimestamp := time.Now()
-	slog.Info("starting backup", "timestamp", backupTimestamp.Format("2006-01-02T15-04-05"))
+	backupTimestamp := s.now()
+	target.stats = &statsRecorder{}
+
+	err := s.runBackup(ctx, target, backupTimestamp)
+
+	stats := target.stats.snapshot()
+	stats.StartedAt = backupTimestamp
+	stats.CompletedAt = s.now()
+	if s.reportFile != "" {
+		// The report is for monitoring, so failing to write it does not fail the backup
+		if reportErr := WriteReport(s.reportFile, stats, err); reportErr != nil {
+			s.logger().Warn("failed to write backup report", "file", s.reportFile, "error", reportErr)
+		}
+	}
+	if s.metricsPublisher != nil {
+		// Like the report, metrics are for monitoring and do not fail the backup
+		if metricsErr := s.metricsPublisher.Publish(ctx, s.bucketName, metrics.BackupMetrics{
+			FilesUploaded: stats.FilesUploaded,
+			FilesFailed:   stats.FilesFailed,
+			BytesUploaded: stats.BytesUploaded,
+			Duration:      stats.CompletedAt.Sub(stats.StartedAt),
+		}); metricsErr != nil {
+			s.logger().Warn("failed to publish backup metrics", "bucket", s.bucketName, "error", metricsErr)
+		}
+	}
+	return stats, err
+}
+
+// runBackup uploads the files of the directories in target under the timestamp prefix of backupTimestamp.
+func (s *Service) runBackup(ctx context.Context, target backupTarget, backupTimestamp time.Time) error {
+	sessionID := nextSessionID()
 
-	files, err := s.collectAllFiles(ctx)
+	// Archive mode packs each directory from the files collected here, so nothing is walked twice
+	collectors, err := s.collectDirs(ctx, target)
 	if err != nil {
-		return fmt.Errorf("%s: failed to collect files: %w", op, err)
+		return fmt.Errorf("failed to collect files: %w", err)
 	}
+	files, emptyDirs := collectedFiles(collectors)
 
-	if err := s.backupAllFiles(ctx, files, backupTimestamp); err != nil {
+	s.logger().Info("starting backup",
+		"session_id", sessionID,
+		"timestamp", backupTimestamp.Format(timestampLayout),
+		"backup_dirs", target.dirs,
+		"recursive", target.recursive,
+		"file_count_estimate", len(files))
+
+	// Refuse to upload a suspiciously small backup, e.g. from an accidentally emptied directory
+	if err := s.checkMinimums(ctx, files); err != nil {
+		return err
+	}
+
+	if s.archiveMode == config.ArchiveModeTarGz {
+		if err := s.backupArchives(ctx, target, collectors, backupTimestamp); err != nil {
+			return err
+		}
+		if s.updateLatest {
+			if err := s.updateLatestPointer(ctx, backupTimestamp.Format(s.prefixLayout())); err != nil {
+				return err
+			}
+		}
+
+		s.logger().Info("backup completed",
+			"session_id", sessionID,
+			"timestamp", backupTimestamp.Format(timestampLayout),
+			"archives", len(target.dirs))
+		return nil
+	}
+
+	err = s.backupAllFiles(ctx, target, files, backupTimestamp)
+	if placeholderErr := s.putPlaceholders(ctx, target, emptyDirs, backupTimestamp); placeholderErr != nil {
+		err = errors.Join(err, fmt.Errorf("one or more empty directory placeholders failed to upload: %w", placeholderErr))
+	}
+
+	// Persist the cache even after partial failures so successful uploads are not repeated
+	if s.cache != nil {
+		if saveErr := s.cache.Save(); saveErr != nil {
+			s.logger().Warn("failed to save incremental backup cache", "file", s.cache.Path(), "error", saveErr)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if s.updateLatest {
+		if err := s.updateLatestPointer(ctx, backupTimestamp.Format(s.prefixLayout())); err != nil {
+			return err
+		}
+	}
+	if s.skipUnchangedDirs {
+		s.recordBackupTime(target.dirs, backupTimestamp)
+	}
+
+	s.logger().Info("backup completed",
+		"session_id", sessionID,
+		"timestamp", backupTimestamp.Format(timestampLayout),
+		"files", len(files))
+	return nil
+}
+
+// backupSessions counts the backups started by this process, across all services.
+var backupSessions atomic.Uint64
+
+// nextSessionID returns an ID that correlates the log records of one backup, so that
+// concurrent backups can be told apart.
+func nextSessionID() string {
+	return strconv.FormatUint(backupSessions.Add(1), 10)
+}
+
+// checkMinimums returns ErrTooFewFiles or ErrTooFewBytes if files are fewer or smaller
+// than the configured minimums.
+func (s *Service) checkMinimums(ctx context.Context, files []string) error {
+	const op = "s3.Service.checkMinimums"
+
+	if len(files) < s.minFiles {
+		return fmt.Errorf("%s: %w: found %d, require at least %d (%s)", op, ErrTooFewFiles,
+			len(files), s.minFiles, config.EnvRequireMinFiles)
+	}
+
+	if s.minBytes <= 0 {
+		return nil
+	}
+
+	total, err := totalSize(ctx, files)
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	slog.Info("backup completed", "timestamp", backupTimestamp.Format("2006-01-02T15-04-05"), "files", len(files))
+	if total < s.minBytes {
+		return fmt.Errorf("%s: %w: found %d bytes, require at least %d (%s)", op, ErrTooFewBytes,
+			total, s.minBytes, config.EnvRequireMinBytes)
+	}
+
 	return nil
 }
 
 // backupAllFiles uploads all provided files to the S3 bucket.
-// It continues processing all files even if some fail, collecting all errors.
-func (s *Service) backupAllFiles(ctx context.Context, files []string, timestamp time.Time) error {
+// It continues processing all files even if some fail, collecting all errors, unless
+// maxErrors uploads fail in a row; it then returns ErrMaxErrorsExceeded without
+// attempting the remaining files.
+func (s *Service) backupAllFiles(ctx context.Context, target backupTarget, files []string, timestamp time.Time) error {
 	const op = "s3.Service.backupAllFiles"
 
 	if len(files) == 0 {
-		slog.Warn("no files to backup")
+		s.logger().Warn("no files to backup")
+		return nil
+	}
+
+	if s.concurrency > 1 {
+		if err := s.backupFilesConcurrently(ctx, target, files, timestamp); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
 		return nil
 	}
 
+	total := len(files)
 	var joinedErrs error
-	for _, file := range files {
+	consecutiveErrors := 0
+	uploaded := 0
+	for i, file := range files {
 		// Check for context cancellation
 		select {
 		case <-ctx.Done():
@@ -144,133 +788,509 @@ func (s *Service) backupAllFiles(ctx context.Context, files []string, timestamp
 		default:
 		}
 
-		if err := s.backupFile(ctx, file, timestamp); err != nil {
+		if err := s.backupFile(ctx, target, file, timestamp); err != nil {
 			joinedErrs = errors.Join(joinedErrs, err)
+			consecutiveErrors++
+		} else {
+			consecutiveErrors = 0
+			uploaded++
+			s.logProgress(uploaded, total)
+		}
+
+		// Stop early during an outage instead of failing every remaining file
+		if s.maxErrors > 0 && consecutiveErrors >= s.maxErrors {
+			return fmt.Errorf("%s: %w: aborted after %d failures in a row, %d files not attempted (%s): %w",
+				op, ErrMaxErrorsExceeded, consecutiveErrors, len(files)-i-1, config.EnvMaxErrors, joinedErrs)
+		}
+	}
+
+	if joinedErrs != nil {
+		return fmt.Errorf("%s: one or more files failed to backup: %w", op, joinedErrs)
+	}
+	return nil
+}
+
+// backupFilesConcurrently uploads files with up to concurrency uploads in flight, of which at most
+// concurrencyPerDir belong to the same backup directory. Every directory gets its own dispatcher,
+// so one large directory cannot starve the others.
+func (s *Service) backupFilesConcurrently(ctx context.Context, target backupTarget, files []string, timestamp time.Time) error {
+	const op = "s3.Service.backupFilesConcurrently"
+
+	perDir := s.concurrencyPerDir
+	if perDir <= 0 {
+		perDir = s.concurrency
+	}
+
+	// Cancelled when too many uploads fail in a row, so the dispatchers stop starting new ones
+	uploadCtx, abort := context.WithCancel(ctx)
+	defer abort()
+
+	global := make(chan struct{}, s.concurrency)
+
+	var (
+		mu                sync.Mutex
+		joinedErrs        error
+		attempted         int
+		uploaded          int
+		consecutiveErrors int
+		aborted           bool
+	)
+	record := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempted++
+		if err == nil {
+			consecutiveErrors = 0
+			uploaded++
+			s.logProgress(uploaded, len(files))
+			return
 		}
+		joinedErrs = errors.Join(joinedErrs, err)
+		consecutiveErrors++
+
+		// Stop early during an outage instead of failing every remaining file
+		if s.maxErrors > 0 && consecutiveErrors >= s.maxErrors && !aborted {
+			aborted = true
+			abort()
+		}
+	}
+
+	var dispatchers sync.WaitGroup
+	for _, dirFiles := range groupFilesByDir(target, files) {
+		dispatchers.Go(func() {
+			dirSem := make(chan struct{}, perDir)
+			var uploads sync.WaitGroup
+			defer uploads.Wait()
+
+			for _, file := range dirFiles {
+				if !acquire(uploadCtx, dirSem) {
+					return
+				}
+				if !acquire(uploadCtx, global) {
+					<-dirSem
+					return
+				}
+
+				uploads.Go(func() {
+					defer func() {
+						<-global
+						<-dirSem
+					}()
+					// acquire may take a free slot even after cancellation, so check again before uploading
+					if uploadCtx.Err() != nil {
+						return
+					}
+					record(s.backupFile(uploadCtx, target, file, timestamp))
+				})
+			}
+		})
 	}
+	dispatchers.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if aborted {
+		return fmt.Errorf("%s: %w: aborted after %d failures in a row, %d files not attempted (%s): %w",
+			op, ErrMaxErrorsExceeded, consecutiveErrors, len(files)-attempted, config.EnvMaxErrors, joinedErrs)
+	}
 	if joinedErrs != nil {
 		return fmt.Errorf("%s: one or more files failed to backup: %w", op, joinedErrs)
 	}
 	return nil
 }
 
+// logProgress logs how many of total files have been uploaded after every progressEvery
+// successful uploads, so long backups show signs of life between their start and end.
+func (s *Service) logProgress(done, total int) {
+	if s.progressEvery <= 0 || done%s.progressEvery != 0 {
+		return
+	}
+
+	s.logger().Info("backup progress",
+		"files_done", done,
+		"files_total", total,
+		"pct", done*100/total)
+}
+
+// acquire takes a slot of the semaphore sem, reporting false if ctx is cancelled first.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// groupFilesByDir groups files by the backup directory they belong to, keeping their order.
+// Files outside every backup directory are grouped under "" so that uploading them reports the error.
+// Groups are keyed by the full directory path rather than the base-directory prefix of the S3 key,
+// since two backup directories may share a base name and absolute path keys have no such prefix.
+func groupFilesByDir(target backupTarget, files []string) map[string][]string {
+	groups := make(map[string][]string, len(target.dirs))
+	for _, file := range files {
+		dir := ""
+		for _, candidate := range target.dirs {
+			if isWithinDir(candidate, file) {
+				dir = candidate
+				break
+			}
+		}
+		groups[dir] = append(groups[dir], file)
+	}
+	return groups
+}
+
+// isWithinDir reports whether file is inside dir. Both paths are resolved to absolute paths
+// first, so a relative backup directory such as ./data matches the files collected from it.
+func isWithinDir(dir, file string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+
+	relPath, err := filepath.Rel(absDir, absFile)
+	return err == nil && !strings.HasPrefix(relPath, "..")
+}
+
 // backupFile uploads a single file to the configured S3 bucket.
 // The S3 object key is constructed with a timestamp prefix and the file's relative path.
-func (s *Service) backupFile(ctx context.Context, fileName string, timestamp time.Time) error {
+// Failures are returned as a *BackupError carrying fileName.
+func (s *Service) backupFile(ctx context.Context, target backupTarget, fileName string, timestamp time.Time) (err error) {
 	const op = "s3.Service.backupFile"
 
+	defer func() {
+		if err != nil {
+			target.stats.recordFailure()
+		}
+	}()
+
 	if fileName == "" {
-		return fmt.Errorf("%s: %w", op, ErrEmptyFilename)
+		return &BackupError{FilePath: fileName, Cause: fmt.Errorf("%s: %w", op, ErrEmptyFilename)}
 	}
 
 	//nolint:gosec // G304: fileName comes from user's configured backup directories
 	file, err := os.Open(fileName)
 	if err != nil {
-		return fmt.Errorf("%s: failed to open file %s: %w", op, fileName, err)
+		return &BackupError{FilePath: fileName, Cause: fmt.Errorf("%s: failed to open file: %w", op, err)}
 	}
 	defer func() {
 		if closeErr := file.Close(); closeErr != nil {
-			slog.Warn("failed to close file", "file", fileName, "error", closeErr)
+			s.logger().Warn("failed to close file", "file", fileName, "error", closeErr)
 		}
 	}()
 
-	s3Key, err := s.buildS3Key(fileName)
+	s3Key, err := s.buildS3Key(target, fileName)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return &BackupError{FilePath: fileName, Cause: fmt.Errorf("%s: %w", op, err)}
 	}
 
 	// Use the provided timestamp for all files in this backup operation
-	key := buildObjectKey(s3Key, timestamp)
+	key := buildObjectKey(s3Key, s.keySeparator(), s.prefixLayout(), timestamp)
+	if err := s.checkKeyLength(key); err != nil {
+		return &BackupError{FilePath: fileName, Cause: fmt.Errorf("%s: %w", op, err)}
+	}
 
-	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: &s.bucketName,
-		Key:    &key,
-		Body:   file,
-	})
+	uploaded := true
+	if s.cache == nil {
+		err = s.putFile(ctx, file, key)
+	} else {
+		uploaded, err = s.putFileIncremental(ctx, file, key)
+	}
+	if err != nil {
+		return &BackupError{FilePath: fileName, Cause: fmt.Errorf("%s: %w", op, err)}
+	}
 
+	if uploaded {
+		target.stats.recordUpload(key, fileSize(file))
+	}
+	return nil
+}
+
+// fileSize returns the size of an open file, or 0 if it cannot be determined.
+func fileSize(file *os.File) int64 {
+	info, err := file.Stat()
 	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// putFileIncremental uploads an open file unless the cache shows it is unchanged since its
+// last upload, and records the uploaded state in the cache on success. It reports whether
+// the file was uploaded.
+func (s *Service) putFileIncremental(ctx context.Context, file *os.File, key string) (bool, error) {
+	const op = "s3.Service.putFileIncremental"
+
+	fileName := file.Name()
+	info, err := file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to stat file %s: %w", op, fileName, err)
+	}
+
+	if s.cache.Lookup(fileName, info) {
+		s.logger().Debug("skipping unchanged file", "file", fileName)
+		return false, nil
+	}
+
+	hash := md5.New() //nolint:gosec // G401: MD5 identifies file contents, it is not used for security
+	if _, err := io.Copy(hash, file); err != nil {
+		return false, fmt.Errorf("%s: failed to hash file %s: %w", op, fileName, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("%s: failed to rewind file %s: %w", op, fileName, err)
+	}
+
+	if err := s.putFile(ctx, file, key); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.cache.Update(fileName, info, hex.EncodeToString(hash.Sum(nil)))
+	return true, nil
+}
+
+// putFile uploads the contents of an open file to the configured S3 bucket under key.
+// If a checksum algorithm is configured, the checksum is sent so S3 can verify the upload.
+// Files larger than the part size are uploaded in parts when multipart uploads are enabled.
+func (s *Service) putFile(ctx context.Context, file *os.File, key string) error {
+	const op = "s3.Service.putFile"
+
+	input := &s3.PutObjectInput{
+		Bucket:       &s.bucketName,
+		Key:          &key,
+		Body:         file,
+		RequestPayer: s.requestPayer(),
+	}
+	s.applyEncryption(input)
+	s.applyObjectLock(input)
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("%s: failed to stat file %s: %w", op, file.Name(), err)
+	}
+	// A known length avoids chunked transfer encoding, which some S3-compatible gateways reject
+	input.ContentLength = aws.Int64(info.Size())
+
+	// Archives are temporary files, so their path and mode describe nothing worth keeping
+	// and their name is taken from the key instead
+	if s.archiveMode == config.ArchiveModeNone {
+		s.applyFileMetadata(input, file.Name(), info)
+		s.applyContentDisposition(input, filepath.Base(file.Name()))
+		s.applyStorageClass(input, filepath.Base(file.Name()))
+	} else {
+		s.applyContentDisposition(input, path.Base(key))
+		s.applyStorageClass(input, path.Base(key))
+	}
+
+	if s.useMultipart(info.Size()) {
+		err = s.putMultipart(ctx, input)
+	} else {
+		if s.checksumAlgorithm != config.ChecksumNone {
+			checksum, checksumErr := fileChecksum(file, s.checksumAlgorithm)
+			if checksumErr != nil {
+				return fmt.Errorf("%s: %w", op, checksumErr)
+			}
+			applyChecksum(input, s.checksumAlgorithm, checksum)
+		}
+
+		_, err = s.client.PutObject(ctx, input)
+	}
+
+	if err != nil {
+		attrs := []any{"bucket", s.bucketName, "key", key, "error", err}
+		if requestID, ok := requestIDFromError(err); ok {
+			attrs = append(attrs, "aws_request_id", requestID)
+		}
+		s.logger().Error("failed to upload object", attrs...)
+
 		return fmt.Errorf("%s: failed to put object to S3 (key=%s): %w", op, key, err)
 	}
 
+	s.logPresignedURL(ctx, key)
 	return nil
 }
 
+// requestIDFromError extracts the AWS request ID from an error returned by the S3 client.
+// It returns false if the error carries no request ID, e.g. for network errors raised
+// before the request reached AWS.
+func requestIDFromError(err error) (string, bool) {
+	var respErr interface{ ServiceRequestID() string }
+	if errors.As(err, &respErr) && respErr.ServiceRequestID() != "" {
+		return respErr.ServiceRequestID(), true
+	}
+	return "", false
+}
+
+// requestPayer returns the request payer to send with object requests,
+// or an empty value unless requester pays is enabled.
+func (s *Service) requestPayer() types.RequestPayer {
+	if !s.requesterPays {
+		return ""
+	}
+	return types.RequestPayerRequester
+}
+
 // buildS3Key constructs an S3 key from the full file path by finding the backup directory
 // it belongs to and creating a relative path with the base directory name as prefix.
 // For example: /data/documents/invoices/invoice-001.txt -> documents/invoices/invoice-001.txt
-func (s *Service) buildS3Key(filePath string) (string, error) {
+// When absolute paths are preserved, the full path without its leading slash is used instead:
+// /data/documents/invoices/invoice-001.txt -> data/documents/invoices/invoice-001.txt
+// Relative paths are resolved against the working directory first.
+func (s *Service) buildS3Key(target backupTarget, filePath string) (string, error) {
 	const op = "s3.Service.buildS3Key"
 
+	absFile, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to resolve absolute path of %s: %w", op, filePath, err)
+	}
+
 	// Find which backup directory this file belongs to
-	for _, dir := range s.backupDirs {
+	for _, dir := range target.dirs {
+		// Resolve relative directories such as "." so their base name is that of the real directory
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return "", fmt.Errorf("%s: failed to resolve absolute path of %s: %w", op, dir, err)
+		}
+
 		// Check if the file path starts with this backup directory
-		relPath, err := filepath.Rel(dir, filePath)
+		relPath, err := filepath.Rel(absDir, absFile)
 		if err != nil || strings.HasPrefix(relPath, "..") {
 			// File is not under this directory, try next one
 			continue
 		}
 
+		if s.preserveAbsolutePath {
+			return absoluteKey(absFile)
+		}
+
 		// Found the matching directory - construct S3 key with base directory name
-		baseDir := filepath.Base(dir)
+		baseDir := filepath.Base(absDir)
 		return filepath.Join(baseDir, relPath), nil
 	}
 
 	return "", fmt.Errorf("%s: file %s does not belong to any configured backup directory", op, filePath)
 }
 
+// absoluteKey converts filePath to an S3 key holding its absolute path.
+// The leading slash is dropped so keys do not start with an empty path segment.
+func absoluteKey(filePath string) (string, error) {
+	const op = "s3.absoluteKey"
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to resolve absolute path of %s: %w", op, filePath, err)
+	}
+
+	return strings.TrimPrefix(filepath.ToSlash(absPath), "/"), nil
+}
+
 // Start begins the scheduled backup process in the background.
 // It runs backups according to the configured cron schedule.
+// Backups missed while the system was suspended are handled according to the missed job policy.
 // The scheduler will stop when the context is cancelled or Stop() is called.
+// Each backup runs with its own context, see jobContext: with a job timeout, a backup in
+// progress when ctx is cancelled keeps running until it finishes or the timeout expires, and
+// Start waits for it before returning.
 func (s *Service) Start(ctx context.Context) error {
 	const op = "s3.Service.Start"
 
 	schedule := s.cronSchedule
 
-	c := cron.New()
-	_, err := c.AddFunc(schedule, func() {
-		// Create a new context for each backup job that respects the parent context
-		backupCtx := ctx
-		if ctx.Err() != nil {
-			slog.Warn("skipping scheduled backup: context cancelled")
-			return
-		}
-		slog.Info("starting scheduled backup", "time", time.Now().Format(time.RFC3339))
-		if err := s.Backup(backupCtx); err != nil {
-			slog.Error("scheduled backup failed", "error", err)
-		} else {
-			slog.Info("scheduled backup completed successfully", "time", time.Now().Format(time.RFC3339))
-		}
-	})
-
+	sched, err := s.parseSchedule(schedule)
 	if err != nil {
 		return fmt.Errorf("%s: invalid cron schedule %q: %w", op, schedule, err)
 	}
+	s.warnLongInterval(sched, s.now())
+
+	if s.runOnStart {
+		if err := s.runStartupBackup(ctx); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// Panics are logged and counted; without a wrapper they would crash the process
+	c := cron.New(cron.WithChain(s.recoverPanics))
+	c.Schedule(sched, cron.FuncJob(func() {
+		if err := s.waitJitter(ctx); err != nil {
+			s.logger().Warn("skipping scheduled backup: stopped while waiting for jitter", "error", err)
+			return
+		}
+		s.runScheduledBackup(ctx)
+	}))
 
 	c.Start()
+	s.setLastRun(s.now())
+
+	// The cron library does not catch up on runs missed while the system was suspended
+	var watcher sync.WaitGroup
+	watcher.Go(func() {
+		s.watchMissedJobs(ctx, sched)
+	})
 
-	slog.Info("backup scheduler started", "schedule", schedule)
+	s.logger().Info("backup scheduler started", "schedule", schedule)
 
 	// Block until stop signal or context cancellation
 	select {
 	case <-s.stopCh:
-		slog.Info("received stop signal")
+		s.logger().Info("received stop signal")
 	case <-ctx.Done():
-		slog.Info("context cancelled, stopping scheduler")
+		s.logger().Info("context cancelled, stopping scheduler")
 	}
 
 	// Graceful shutdown
+	watcher.Wait()
 	shutdownCtx := c.Stop()
 	<-shutdownCtx.Done()
 
-	slog.Info("backup scheduler stopped")
+	s.logger().Info("backup scheduler stopped")
 	return nil
 }
 
-// Stop gracefully stops the scheduled backup process.
-// It is safe to call multiple times.
-func (s *Service) Stop() {
+// Close stops the scheduler, waits for a running scheduled backup to finish, and saves the
+// incremental backup cache. Unlike Stop, which only signals the scheduler and returns
+// immediately, Close blocks until the service no longer uses any resources.
+// It is safe to call multiple times; later calls return the result of the first.
+func (s *Service) Close() error {
+	const op = "s3.Service.Close"
+
+	s.closeOnce.Do(func() {
+		s.Stop()
+
+		// A scheduled backup holds a run slot until it has finished uploading, so taking all of
+		// them waits for every running one
+		slots := s.scheduledRunSlots()
+		for range cap(slots) {
+			slots <- struct{}{}
+		}
+		defer func() {
+			for range cap(slots) {
+				<-slots
+			}
+		}()
+
+		if s.cache != nil {
+			if err := s.cache.Save(); err != nil {
+				s.closeErr = fmt.Errorf("%s: failed to save incremental backup cache: %w", op, err)
+			}
+		}
+	})
+	return s.closeErr
+}
+
+// Stop gracefully stops the scheduled backup process. It does not wait for a running
+// backup to finish; use Close for that.
+// It is safe to call multiple times; it returns true only for the call that stopped the service.
+func (s *Service) Stop() bool {
+	stopped := false
 	s.stopOnce.Do(func() {
 		close(s.stopCh)
+		stopped = true
 	})
+	return stopped
 }
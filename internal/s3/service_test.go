@@ -3,15 +3,29 @@ package s3
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"s3-backup/internal/cache"
 	"s3-backup/internal/config"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -100,6 +114,235 @@ func TestNewS3Service(t *testing.T) {
 	}
 }
 
+func TestNewS3Service_UserAgent(t *testing.T) {
+	t.Parallel()
+
+	userAgents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		_, _ = io.WriteString(w, "<VersioningConfiguration/>")
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := createTestConfig(t, 1, false)
+	cfg.UserAgent = "s3-backup/1.2.3"
+
+	svc, err := NewS3Service(context.Background(), cfg, WithClientOptions(testServerOptions(server.URL)))
+	require.NoError(t, err)
+
+	_, err = svc.BucketVersioningStatus(context.Background())
+	require.NoError(t, err)
+
+	userAgent := <-userAgents
+	assert.Contains(t, userAgent, "aws-sdk-go-v2")
+	assert.Contains(t, userAgent, "s3-backup/1.2.3")
+}
+
+func TestNewS3Service_EndpointRegion(t *testing.T) {
+	t.Parallel()
+
+	authorizations := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations <- r.Header.Get("Authorization")
+		_, _ = io.WriteString(w, "<VersioningConfiguration/>")
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := createTestConfig(t, 1, false)
+	cfg.AWSRegion = "us-west-2"
+	cfg.S3EndpointRegion = "minio-local"
+
+	svc, err := NewS3Service(context.Background(), cfg, WithClientOptions(testServerOptions(server.URL), func(o *s3.Options) {
+		o.Credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+		})
+	}))
+	require.NoError(t, err)
+
+	_, err = svc.BucketVersioningStatus(context.Background())
+	require.NoError(t, err)
+
+	authorization := <-authorizations
+	assert.Contains(t, authorization, "/minio-local/s3/aws4_request")
+	assert.NotContains(t, authorization, "us-west-2")
+}
+
+func TestClientOptions(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		cfg            *config.Config
+		wantPathStyle  bool
+		wantAccelerate bool
+	}{
+		"defaults": {
+			cfg: &config.Config{},
+		},
+		"path style": {
+			cfg:           &config.Config{UsePathStyle: true},
+			wantPathStyle: true,
+		},
+		"transfer acceleration": {
+			cfg:            &config.Config{TransferAcceleration: true},
+			wantAccelerate: true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var opts s3.Options
+			for _, fn := range clientOptions(tc.cfg) {
+				fn(&opts)
+			}
+
+			assert.Equal(t, tc.wantPathStyle, opts.UsePathStyle)
+			assert.Equal(t, tc.wantAccelerate, opts.UseAccelerate)
+		})
+	}
+}
+
+func TestNewS3Service_PreflightCheck(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		preflight     bool
+		shouldFail    bool
+		wantErr       error
+		wantHeadCalls int
+	}{
+		"preflight disabled makes no request": {
+			shouldFail: true,
+		},
+		"accessible bucket": {
+			preflight:     true,
+			wantHeadCalls: 1,
+		},
+		"inaccessible bucket fails": {
+			preflight:     true,
+			shouldFail:    true,
+			wantErr:       ErrBucketNotAccessible,
+			wantHeadCalls: 1,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := createTestConfig(t, 1, false)
+			cfg.PreflightCheck = tc.preflight
+			client := &mockS3Client{shouldFail: tc.shouldFail}
+
+			svc, err := NewS3Service(context.Background(), cfg, WithClient(client))
+
+			assert.Equal(t, tc.wantHeadCalls, client.headBucketCalls)
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.ErrorIs(t, err, errMockS3Failure)
+				assert.Nil(t, svc)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Same(t, client, svc.client)
+		})
+	}
+}
+
+func TestService_ReloadConfig(t *testing.T) {
+	t.Parallel()
+
+	oldDirs := createTempDirs(t, 1)
+	svc := &Service{client: &mockS3Client{}, bucketName: "test-bucket", backupDirs: oldDirs}
+
+	cfg := createTestConfig(t, 2, true)
+	require.NoError(t, svc.ReloadConfig(cfg))
+	assert.Equal(t, cfg.GetBackupDirs(), svc.getBackupDirs())
+	assert.True(t, svc.isRecursive())
+
+	err := svc.ReloadConfig(&config.Config{BackupDirs: []string{"/nonexistent/path"}})
+	require.ErrorIs(t, err, ErrDirectoryNotFound)
+	assert.Equal(t, cfg.GetBackupDirs(), svc.getBackupDirs())
+	assert.True(t, svc.isRecursive())
+
+	require.ErrorIs(t, svc.ReloadConfig(nil), ErrNilConfig)
+}
+
+func TestService_Config(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := createTestConfig(t, 1, false)
+	cfg.KMSKeyID = "alias/backups"
+	cfg.KMSContext = map[string]string{"team": "platform"}
+
+	svc, err := NewS3Service(ctx, cfg, WithClient(&mockS3Client{}))
+	require.NoError(t, err)
+
+	got := svc.Config()
+	assert.Equal(t, cfg, got)
+
+	// The copy shares nothing with the service
+	got.BackupDirs[0] = "/elsewhere"
+	got.KMSContext["team"] = "security"
+	assert.Equal(t, cfg, svc.Config())
+
+	// Reloaded settings are reflected, the others are kept
+	reloaded := createTestConfig(t, 2, true)
+	require.NoError(t, svc.ReloadConfig(reloaded))
+	want := cfg.Clone()
+	want.BackupDirs = reloaded.GetBackupDirs()
+	want.Recursive = true
+	assert.Equal(t, want, svc.Config())
+
+	// WithConfig replaces the config that is read back
+	override := cfg.Clone()
+	override.CronSchedule = "@hourly"
+	svc, err = NewS3Service(ctx, cfg, WithClient(&mockS3Client{}), WithConfig(override))
+	require.NoError(t, err)
+	assert.Equal(t, override, svc.Config())
+	assert.NotSame(t, override, svc.Config())
+
+	assert.Nil(t, (&Service{}).Config())
+}
+
+// Run with -race to detect unsynchronized access to the reloadable settings.
+func TestService_ReloadConfig_ConcurrentBackup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dirs := createTempDirs(t, 2)
+	for _, dir := range dirs {
+		createFile(t, dir, "file.txt", "content")
+	}
+
+	svc := &Service{client: &mockS3Client{}, bucketName: "test-bucket", backupDirs: dirs[:1], cfg: &config.Config{BackupDirs: dirs[:1]}}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			cfg := &config.Config{BackupDirs: dirs[:i%2+1], Recursive: i%2 == 0}
+			assert.NoError(t, svc.ReloadConfig(cfg))
+		}
+	})
+
+	for range 20 {
+		require.NoError(t, svc.Backup(ctx))
+		assert.NotEmpty(t, svc.Config().BackupDirs)
+	}
+	close(done)
+	wg.Wait()
+}
+
 func TestValidateDirectories(t *testing.T) {
 	t.Parallel()
 
@@ -137,6 +380,18 @@ func TestValidateDirectories(t *testing.T) {
 			},
 			wantErr: ErrNotADirectory,
 		},
+		"unreadable directory": {
+			setup: func(t *testing.T) []string {
+				if os.Geteuid() == 0 {
+					t.Skip("root can read directories regardless of their permissions")
+				}
+				dir := t.TempDir()
+				require.NoError(t, os.Chmod(dir, 0))
+				t.Cleanup(func() { _ = os.Chmod(dir, 0750) }) //nolint:gosec // G302: restore permissions for cleanup
+				return []string{dir}
+			},
+			wantErr: ErrDirectoryNotReadable,
+		},
 		"mix of valid and invalid": {
 			setup: func(t *testing.T) []string {
 				dirs := createTempDirs(t, 1)
@@ -241,7 +496,7 @@ func TestService_BackupAllFiles(t *testing.T) {
 			svc := &Service{bucketName: "test-bucket"}
 
 			timestamp := time.Now()
-			err := svc.backupAllFiles(ctx, tc.files, timestamp)
+			err := svc.backupAllFiles(ctx, svc.snapshotTarget(), tc.files, timestamp)
 
 			if tc.wantErr {
 				require.Error(t, err)
@@ -262,7 +517,7 @@ func TestService_BackupAllFiles_ContextCancellation(t *testing.T) {
 	files := []string{"file1.txt", "file2.txt"}
 
 	timestamp := time.Now()
-	err := svc.backupAllFiles(ctx, files, timestamp)
+	err := svc.backupAllFiles(ctx, svc.snapshotTarget(), files, timestamp)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -334,15 +589,174 @@ func TestService_BackupFile(t *testing.T) {
 
 			svc, fileName := tc.setup(t)
 			timestamp := time.Now()
-			err := svc.backupFile(ctx, fileName, timestamp)
+			err := svc.backupFile(ctx, svc.snapshotTarget(), fileName, timestamp)
+
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_BackupFile_PreserveAbsolutePath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "log"), 0750))
+	createFile(t, filepath.Join(dir, "log"), "app.log", "content")
+	filePath := filepath.Join(dir, "log", "app.log")
+
+	client := &mockS3Client{}
+	svc := &Service{
+		client:               client,
+		bucketName:           "test-bucket",
+		backupDirs:           []string{dir},
+		preserveAbsolutePath: true,
+	}
+
+	timestamp := time.Now()
+	require.NoError(t, svc.backupFile(context.Background(), svc.snapshotTarget(), filePath, timestamp))
+	require.Len(t, client.putKeys, 1)
+
+	key := client.putKeys[0]
+	assert.Equal(t, timestamp.Format(timestampLayout)+"/"+strings.TrimPrefix(filepath.ToSlash(filePath), "/"), key)
+
+	// Restoring under a target directory recreates the original absolute structure
+	target := t.TempDir()
+	relKey := strings.TrimPrefix(key, timestamp.Format(timestampLayout)+"/")
+	assert.Equal(t, filepath.Join(target, filePath), filepath.Join(target, filepath.FromSlash(relKey)))
+}
+
+func TestService_Backup_Minimums(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		minFiles    int
+		minBytes    int64
+		archiveMode string
+		wantErr     error
+		wantPuts    int
+	}{
+		"too few files": {
+			minFiles: 2,
+			wantErr:  ErrTooFewFiles,
+		},
+		"too few files in archive mode": {
+			minFiles:    2,
+			archiveMode: config.ArchiveModeTarGz,
+			wantErr:     ErrTooFewFiles,
+		},
+		"too few bytes": {
+			minBytes: 1024,
+			wantErr:  ErrTooFewBytes,
+		},
+		"minimums met": {
+			minFiles: 1,
+			minBytes: int64(len("content")),
+			wantPuts: 1,
+		},
+		"no minimums": {
+			wantPuts: 1,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, "only.txt", "content")
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:      client,
+				bucketName:  "test-bucket",
+				backupDirs:  []string{dir},
+				archiveMode: tc.archiveMode,
+				minFiles:    tc.minFiles,
+				minBytes:    tc.minBytes,
+			}
+
+			err := svc.Backup(ctx)
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Empty(t, client.putKeys, "nothing must be uploaded")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, client.putKeys, tc.wantPuts)
+		})
+	}
+}
+
+func TestService_BackupDir(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := t.TempDir()
+	documents := filepath.Join(root, "documents")
+	photos := filepath.Join(root, "photos")
+	require.NoError(t, os.Mkdir(documents, 0750))
+	require.NoError(t, os.Mkdir(photos, 0750))
+	createFile(t, documents, "report.txt", "report")
+	createFile(t, photos, "beach.jpg", "beach")
+
+	tc := map[string]struct {
+		dir      string
+		wantErr  error
+		wantKeys []string
+	}{
+		"configured directory": {
+			dir:      documents,
+			wantKeys: []string{"documents/report.txt"},
+		},
+		"configured directory in another form": {
+			dir:      documents + string(filepath.Separator) + ".",
+			wantKeys: []string{"documents/report.txt"},
+		},
+		"directory not configured": {
+			dir:     root,
+			wantErr: ErrDirectoryNotConfigured,
+		},
+		"empty directory": {
+			wantErr: ErrEmptyDirectory,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:     client,
+				bucketName: "test-bucket",
+				backupDirs: []string{documents, photos},
+				recursive:  true,
+			}
 
+			err := svc.BackupDir(ctx, tc.dir)
 			if tc.wantErr != nil {
 				require.Error(t, err)
 				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Empty(t, client.putKeys, "nothing must be uploaded")
 				return
 			}
 
 			require.NoError(t, err)
+			require.Len(t, client.putKeys, len(tc.wantKeys))
+			for i, key := range client.putKeys {
+				_, rest, _ := strings.Cut(key, "/")
+				assert.Equal(t, tc.wantKeys[i], rest)
+			}
 		})
 	}
 }
@@ -425,7 +839,7 @@ func TestService_BackupAllFiles_WithErrors(t *testing.T) {
 
 			svc, files := tc.setup(t)
 			timestamp := time.Now()
-			err := svc.backupAllFiles(ctx, files, timestamp)
+			err := svc.backupAllFiles(ctx, svc.snapshotTarget(), files, timestamp)
 
 			if tc.wantErr {
 				require.Error(t, err)
@@ -440,110 +854,1011 @@ func TestService_BackupAllFiles_WithErrors(t *testing.T) {
 	}
 }
 
-func TestService_BuildS3Key(t *testing.T) {
+func TestService_BackupAllFiles_BackupError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "good.txt", "content")
+	good := filepath.Join(dir, "good.txt")
+	missing := filepath.Join(dir, "missing.txt")
+
+	svc := &Service{client: &mockS3Client{}, bucketName: "test-bucket", backupDirs: []string{dir}}
+
+	err := svc.backupAllFiles(context.Background(), svc.snapshotTarget(), []string{good, missing}, time.Now())
+	require.Error(t, err)
+
+	var backupErr *BackupError
+	require.ErrorAs(t, err, &backupErr)
+	assert.Equal(t, missing, backupErr.FilePath)
+	require.ErrorIs(t, backupErr, os.ErrNotExist)
+	assert.Contains(t, err.Error(), "(file="+missing+")")
+}
+
+func TestService_BackupFile_ContentLength(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "hello world")
+
+	client := &mockS3Client{}
+	svc := &Service{client: client, bucketName: "test-bucket", backupDirs: []string{dir}}
+
+	require.NoError(t, svc.backupFile(context.Background(), svc.snapshotTarget(), filepath.Join(dir, "file.txt"), time.Now()))
+
+	require.Len(t, client.putInputs, 1)
+	require.NotNil(t, client.putInputs[0].ContentLength)
+	assert.Equal(t, int64(len("hello world")), *client.putInputs[0].ContentLength)
+	assert.Equal(t, client.putSizes[0], *client.putInputs[0].ContentLength)
+}
+
+func TestService_RequesterPays(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	tc := map[string]struct {
-		setup      func(t *testing.T) (svc *Service, filePath, expectedKey string)
-		wantErr    bool
-		errContain string
+		requesterPays bool
+		want          types.RequestPayer
 	}{
-		"file in single backup directory": {
-			setup: func(t *testing.T) (*Service, string, string) {
-				dir := t.TempDir()
-				filePath := filepath.Join(dir, "test.txt")
-				svc := &Service{
-					backupDirs: []string{dir},
-				}
-				expectedKey := filepath.Join(filepath.Base(dir), "test.txt")
-				return svc, filePath, expectedKey
-			},
-		},
-		"file in subdirectory": {
-			setup: func(t *testing.T) (*Service, string, string) {
-				dir := t.TempDir()
-				filePath := filepath.Join(dir, "subdir", "nested", "file.txt")
-				svc := &Service{
-					backupDirs: []string{dir},
-				}
-				expectedKey := filepath.Join(filepath.Base(dir), "subdir", "nested", "file.txt")
-				return svc, filePath, expectedKey
-			},
-		},
-		"file in second backup directory": {
-			setup: func(t *testing.T) (*Service, string, string) {
-				dir1 := t.TempDir()
-				dir2 := t.TempDir()
-				filePath := filepath.Join(dir2, "docs", "report.pdf")
-				svc := &Service{
-					backupDirs: []string{dir1, dir2},
-				}
-				expectedKey := filepath.Join(filepath.Base(dir2), "docs", "report.pdf")
-				return svc, filePath, expectedKey
-			},
-		},
-		"file not in any backup directory": {
-			setup: func(t *testing.T) (*Service, string, string) {
-				dir := t.TempDir()
-				svc := &Service{
-					backupDirs: []string{dir},
-				}
-				return svc, "/completely/different/path/file.txt", ""
-			},
-			wantErr:    true,
-			errContain: "does not belong to any configured backup directory",
-		},
-		"empty backup directories": {
-			setup: func(_ *testing.T) (*Service, string, string) {
-				svc := &Service{
-					backupDirs: []string{},
-				}
-				return svc, "/some/file.txt", ""
-			},
-			wantErr:    true,
-			errContain: "does not belong to any configured backup directory",
-		},
+		"enabled":  {requesterPays: true, want: types.RequestPayerRequester},
+		"disabled": {requesterPays: false},
 	}
 
 	for name, tc := range tc {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			svc, filePath, expectedKey := tc.setup(t)
-			key, err := svc.buildS3Key(filePath)
+			dir := t.TempDir()
+			createFile(t, dir, "file.txt", "content")
 
-			if tc.wantErr {
-				require.Error(t, err)
-				assert.Contains(t, err.Error(), tc.errContain)
-				return
+			client := &mockS3Client{objects: []string{"2000-01-01T00-00-00/docs/old.txt"}}
+			svc := &Service{
+				client:        client,
+				bucketName:    "test-bucket",
+				backupDirs:    []string{dir},
+				requesterPays: tc.requesterPays,
+				retentionDays: 1,
 			}
 
-			require.NoError(t, err)
-			assert.Equal(t, expectedKey, key)
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filepath.Join(dir, "file.txt"), time.Now()))
+			require.NoError(t, svc.PruneOldBackups(ctx))
+
+			require.Len(t, client.putInputs, 1)
+			assert.Equal(t, tc.want, client.putInputs[0].RequestPayer)
+			require.NotEmpty(t, client.listInputs)
+			for _, input := range client.listInputs {
+				assert.Equal(t, tc.want, input.RequestPayer)
+			}
 		})
 	}
 }
 
-// mockS3Client is a simple mock for testing without actual AWS calls.
+func TestService_BackupAllFiles_MaxErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		maxErrors   int
+		failing     []string
+		wantErr     error
+		wantPuts    int
+		wantFailure int
+	}{
+		"consecutive failures abort": {
+			maxErrors:   2,
+			failing:     []string{"f1", "f2", "f3", "f4"},
+			wantErr:     ErrMaxErrorsExceeded,
+			wantPuts:    1,
+			wantFailure: 2,
+		},
+		"success resets the count": {
+			maxErrors:   2,
+			failing:     []string{"f1", "f3", "f5"},
+			wantPuts:    3,
+			wantFailure: 3,
+		},
+		"no limit attempts every file": {
+			failing:     []string{"f0", "f1", "f2", "f3", "f4", "f5"},
+			wantPuts:    0,
+			wantFailure: 6,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			var files []string
+			for i := range 6 {
+				files = append(files, filepath.Join(dir, fmt.Sprintf("f%d", i)))
+				createFile(t, dir, fmt.Sprintf("f%d", i), "content")
+			}
+
+			client := &mockS3Client{failKey: func(key string) bool {
+				return slices.Contains(tc.failing, path.Base(key))
+			}}
+			svc := &Service{
+				client:     client,
+				bucketName: "test-bucket",
+				backupDirs: []string{dir},
+				maxErrors:  tc.maxErrors,
+			}
+
+			err := svc.backupAllFiles(ctx, svc.snapshotTarget(), files, time.Now())
+			require.Error(t, err)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NotErrorIs(t, err, ErrMaxErrorsExceeded)
+			}
+			assert.Len(t, client.putKeys, tc.wantPuts)
+			assert.Equal(t, tc.wantFailure, strings.Count(err.Error(), "mock S3 failure"))
+		})
+	}
+}
+
+func TestService_BackupAllFiles_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		concurrency       int
+		concurrencyPerDir int
+		wantMaxPerDir     int
+	}{
+		"per directory limit below global limit": {
+			concurrency:       4,
+			concurrencyPerDir: 1,
+			wantMaxPerDir:     1,
+		},
+		"per directory limit defaults to global limit": {
+			concurrency:   2,
+			wantMaxPerDir: 2,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dirs := createTempDirs(t, 3)
+			var files []string
+			for _, dir := range dirs {
+				for i := range 4 {
+					createFile(t, dir, fmt.Sprintf("f%d", i), "content")
+					files = append(files, filepath.Join(dir, fmt.Sprintf("f%d", i)))
+				}
+			}
+
+			client := &concurrencyTrackingClient{mockS3Client: &mockS3Client{}, inFlight: make(map[string]int), maxPerDir: make(map[string]int)}
+			svc := &Service{
+				client:            client,
+				bucketName:        "test-bucket",
+				backupDirs:        dirs,
+				concurrency:       tc.concurrency,
+				concurrencyPerDir: tc.concurrencyPerDir,
+			}
+
+			require.NoError(t, svc.backupAllFiles(ctx, svc.snapshotTarget(), files, time.Now()))
+
+			assert.Len(t, client.putKeys, len(files))
+			assert.LessOrEqual(t, client.maxTotal, tc.concurrency)
+			require.Len(t, client.maxPerDir, len(dirs))
+			for dir, got := range client.maxPerDir {
+				assert.LessOrEqual(t, got, tc.wantMaxPerDir, "directory %s", dir)
+			}
+		})
+	}
+}
+
+func TestService_BackupAllFiles_ConcurrentMaxErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var files []string
+	for i := range 20 {
+		createFile(t, dir, fmt.Sprintf("f%d", i), "content")
+		files = append(files, filepath.Join(dir, fmt.Sprintf("f%d", i)))
+	}
+
+	svc := &Service{
+		client:      &mockS3Client{shouldFail: true},
+		bucketName:  "test-bucket",
+		backupDirs:  []string{dir},
+		maxErrors:   2,
+		concurrency: 2,
+	}
+
+	err := svc.backupAllFiles(context.Background(), svc.snapshotTarget(), files, time.Now())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrMaxErrorsExceeded)
+	assert.Less(t, strings.Count(err.Error(), "mock S3 failure"), len(files), "remaining files should not be attempted")
+}
+
+func TestService_BackupAllFiles_ConcurrentCancel(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		cancelFirst bool
+		wantCalls   int32
+	}{
+		"cancelled during first upload": {
+			wantCalls: 1,
+		},
+		"cancelled before backup": {
+			cancelFirst: true,
+			wantCalls:   0,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			var files []string
+			for i := range 10 {
+				createFile(t, dir, fmt.Sprintf("f%d", i), "content")
+				files = append(files, filepath.Join(dir, fmt.Sprintf("f%d", i)))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancelFirst {
+				cancel()
+			}
+
+			client := &cancellingClient{mockS3Client: &mockS3Client{}, cancel: cancel}
+			svc := &Service{
+				client:     client,
+				bucketName: "test-bucket",
+				backupDirs: []string{dir},
+				// One upload at a time per directory, so the next upload waits for the cancelled one
+				concurrency:       4,
+				concurrencyPerDir: 1,
+			}
+
+			err := svc.backupAllFiles(ctx, svc.snapshotTarget(), files, time.Now())
+			require.Error(t, err)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, tc.wantCalls, client.calls.Load(), "no upload should start after cancellation")
+		})
+	}
+}
+
+func TestService_BackupAllFiles_Progress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		progressEvery int
+		concurrency   int
+		wantDone      []int64
+	}{
+		"every file": {
+			progressEvery: 1,
+			wantDone:      []int64{1, 2, 3, 4},
+		},
+		"every other file": {
+			progressEvery: 2,
+			wantDone:      []int64{2, 4},
+		},
+		"every file with concurrent uploads": {
+			progressEvery: 1,
+			concurrency:   3,
+			wantDone:      []int64{1, 2, 3, 4},
+		},
+		"disabled": {},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			var files []string
+			for i := range 4 {
+				createFile(t, dir, fmt.Sprintf("f%d", i), "content")
+				files = append(files, filepath.Join(dir, fmt.Sprintf("f%d", i)))
+			}
+
+			logs := &logRecorder{}
+			svc := &Service{
+				client:        &mockS3Client{},
+				bucketName:    "test-bucket",
+				backupDirs:    []string{dir},
+				progressEvery: tc.progressEvery,
+				concurrency:   tc.concurrency,
+				log:           slog.New(logs),
+			}
+
+			require.NoError(t, svc.backupAllFiles(ctx, svc.snapshotTarget(), files, time.Now()))
+
+			var gotDone []int64
+			for _, record := range logs.findAll("backup progress") {
+				assert.Equal(t, slog.LevelInfo, record.Level)
+				attrs := recordAttrs(record)
+				assert.Equal(t, int64(len(files)), attrs["files_total"])
+				assert.Equal(t, attrs["files_done"].(int64)*100/int64(len(files)), attrs["pct"])
+				gotDone = append(gotDone, attrs["files_done"].(int64))
+			}
+			assert.Equal(t, tc.wantDone, gotDone)
+		})
+	}
+}
+
+func TestGroupFilesByDir(t *testing.T) {
+	t.Parallel()
+
+	target := backupTarget{dirs: []string{"/a/data", "/b/data"}}
+	files := []string{"/a/data/f1", "/b/data/f2", "/a/data/sub/f3", "/c/f4"}
+
+	assert.Equal(t, map[string][]string{
+		"/a/data": {"/a/data/f1", "/a/data/sub/f3"},
+		"/b/data": {"/b/data/f2"},
+		"":        {"/c/f4"},
+	}, groupFilesByDir(target, files))
+}
+
+// concurrencyTrackingClient records the highest number of uploads in flight, in total and per
+// backup directory, identified by the first path segment after the timestamp prefix.
+type concurrencyTrackingClient struct {
+	*mockS3Client
+
+	trackMu   sync.Mutex
+	total     int
+	maxTotal  int
+	inFlight  map[string]int
+	maxPerDir map[string]int
+}
+
+func (c *concurrencyTrackingClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	dir := strings.Split(*params.Key, "/")[1]
+
+	c.trackMu.Lock()
+	c.total++
+	c.inFlight[dir]++
+	c.maxTotal = max(c.maxTotal, c.total)
+	c.maxPerDir[dir] = max(c.maxPerDir[dir], c.inFlight[dir])
+	c.trackMu.Unlock()
+
+	// Keep the upload in flight long enough for others to overlap with it
+	time.Sleep(5 * time.Millisecond)
+
+	c.trackMu.Lock()
+	c.total--
+	c.inFlight[dir]--
+	c.trackMu.Unlock()
+
+	return c.mockS3Client.PutObject(ctx, params, optFns...)
+}
+
+// cancellingClient cancels the backup as soon as the first upload begins and fails every upload
+// with the error of its context.
+type cancellingClient struct {
+	*mockS3Client
+
+	cancel context.CancelFunc
+	calls  atomic.Int32
+}
+
+func (c *cancellingClient) PutObject(ctx context.Context, _ *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.calls.Add(1)
+	c.cancel()
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestService_BackupFile_LogsRequestID(t *testing.T) {
+	// Not run in parallel because it replaces the default logger
+
+	tc := map[string]struct {
+		requestID     string
+		wantRequestID bool
+	}{
+		"error with request ID": {
+			requestID:     "mock-request-id",
+			wantRequestID: true,
+		},
+		"error without request ID": {
+			wantRequestID: false,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			dir := t.TempDir()
+			createFile(t, dir, "test.txt", "test content")
+
+			svc := &Service{
+				client:     &mockS3Client{shouldFail: true, requestID: tc.requestID},
+				bucketName: "test-bucket",
+				backupDirs: []string{dir},
+			}
+
+			err := svc.backupFile(context.Background(), svc.snapshotTarget(), filepath.Join(dir, "test.txt"), time.Now())
+			require.Error(t, err)
+			assert.ErrorIs(t, err, errMockS3Failure)
+
+			record, ok := logs.find("failed to upload object")
+			require.True(t, ok, "expected upload failure to be logged")
+			assert.Equal(t, slog.LevelError, record.Level)
+
+			attrs := recordAttrs(record)
+			assert.Equal(t, "test-bucket", attrs["bucket"])
+			requestID, found := attrs["aws_request_id"]
+			assert.Equal(t, tc.wantRequestID, found)
+			if tc.wantRequestID {
+				assert.Equal(t, tc.requestID, requestID)
+			}
+		})
+	}
+}
+
+func TestService_Backup_LogsStartEvent(t *testing.T) {
+	// Not run in parallel because it replaces the default logger
+	logs := captureLogs(t)
+
+	dir := t.TempDir()
+	createFile(t, dir, "file1.txt", "content1")
+	createFile(t, dir, "file2.txt", "content2")
+
+	svc := &Service{
+		client:     &mockS3Client{},
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		recursive:  true,
+	}
+
+	require.NoError(t, svc.Backup(context.Background()))
+	require.NoError(t, svc.Backup(context.Background()))
+
+	var starts []map[string]any
+	for _, record := range logs.records {
+		if record.Message == "starting backup" {
+			starts = append(starts, recordAttrs(record))
+		}
+	}
+	require.Len(t, starts, 2)
+
+	attrs := starts[0]
+	assert.Equal(t, []string{dir}, attrs["backup_dirs"])
+	assert.Equal(t, true, attrs["recursive"])
+	assert.EqualValues(t, 2, attrs["file_count_estimate"])
+	assert.NotEmpty(t, attrs["session_id"])
+	assert.NotEqual(t, attrs["session_id"], starts[1]["session_id"], "each backup should get its own session")
+
+	completed, ok := logs.find("backup completed")
+	require.True(t, ok, "expected backup completion to be logged")
+	assert.Equal(t, attrs["session_id"], recordAttrs(completed)["session_id"])
+}
+
+func TestService_BackupFile_Incremental(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "test.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("test content"), 0600))
+
+	fileCache, err := cache.Load(filepath.Join(t.TempDir(), "cache.db"))
+	require.NoError(t, err)
+
+	client := &mockS3Client{}
+	svc := &Service{
+		client:     client,
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		cache:      fileCache,
+	}
+
+	// Cache miss uploads the file
+	require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+	assert.Len(t, client.putKeys, 1)
+
+	// Cache hit skips the upload
+	require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+	assert.Len(t, client.putKeys, 1, "unchanged file should not be uploaded again")
+
+	// Changed file invalidates the entry and uploads again
+	require.NoError(t, os.WriteFile(filePath, []byte("changed test content"), 0600))
+	require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+	assert.Len(t, client.putKeys, 2, "changed file should be uploaded")
+}
+
+func TestService_BackupFile_IncrementalFailureNotCached(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "test.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("test content"), 0600))
+
+	fileCache, err := cache.Load(filepath.Join(t.TempDir(), "cache.db"))
+	require.NoError(t, err)
+
+	client := &mockS3Client{shouldFail: true}
+	svc := &Service{
+		client:     client,
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		cache:      fileCache,
+	}
+
+	require.Error(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+
+	// Once S3 recovers, the file must still be uploaded
+	client.shouldFail = false
+	require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filePath, time.Now()))
+	assert.Len(t, client.putKeys, 1)
+}
+
+func TestService_Backup_SavesIncrementalCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	createFile(t, dir, "file.txt", "content")
+
+	cachePath := filepath.Join(t.TempDir(), "cache.db")
+	fileCache, err := cache.Load(cachePath)
+	require.NoError(t, err)
+
+	svc := &Service{
+		client:     &mockS3Client{},
+		bucketName: "test-bucket",
+		backupDirs: []string{dir},
+		cache:      fileCache,
+	}
+
+	require.NoError(t, svc.Backup(context.Background()))
+
+	reloaded, err := cache.Load(cachePath)
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dir, "file.txt"))
+	require.NoError(t, err)
+	assert.True(t, reloaded.Lookup(filepath.Join(dir, "file.txt"), info))
+}
+
+func TestService_BuildS3Key(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		setup      func(t *testing.T) (svc *Service, filePath, expectedKey string)
+		wantErr    bool
+		errContain string
+	}{
+		"file in single backup directory": {
+			setup: func(t *testing.T) (*Service, string, string) {
+				dir := t.TempDir()
+				filePath := filepath.Join(dir, "test.txt")
+				svc := &Service{
+					backupDirs: []string{dir},
+				}
+				expectedKey := filepath.Join(filepath.Base(dir), "test.txt")
+				return svc, filePath, expectedKey
+			},
+		},
+		"file in subdirectory": {
+			setup: func(t *testing.T) (*Service, string, string) {
+				dir := t.TempDir()
+				filePath := filepath.Join(dir, "subdir", "nested", "file.txt")
+				svc := &Service{
+					backupDirs: []string{dir},
+				}
+				expectedKey := filepath.Join(filepath.Base(dir), "subdir", "nested", "file.txt")
+				return svc, filePath, expectedKey
+			},
+		},
+		"file in second backup directory": {
+			setup: func(t *testing.T) (*Service, string, string) {
+				dir1 := t.TempDir()
+				dir2 := t.TempDir()
+				filePath := filepath.Join(dir2, "docs", "report.pdf")
+				svc := &Service{
+					backupDirs: []string{dir1, dir2},
+				}
+				expectedKey := filepath.Join(filepath.Base(dir2), "docs", "report.pdf")
+				return svc, filePath, expectedKey
+			},
+		},
+		"absolute path preserved": {
+			setup: func(t *testing.T) (*Service, string, string) {
+				dir := t.TempDir()
+				filePath := filepath.Join(dir, "logs", "app.log")
+				svc := &Service{
+					backupDirs:           []string{dir},
+					preserveAbsolutePath: true,
+				}
+				expectedKey := strings.TrimPrefix(filepath.ToSlash(filePath), "/")
+				return svc, filePath, expectedKey
+			},
+		},
+		"absolute path preserved for relative backup directory": {
+			setup: func(t *testing.T) (*Service, string, string) {
+				wd, err := os.Getwd()
+				require.NoError(t, err)
+				svc := &Service{
+					backupDirs:           []string{"testdata"},
+					preserveAbsolutePath: true,
+				}
+				expectedKey := strings.TrimPrefix(filepath.ToSlash(filepath.Join(wd, "testdata", "app.log")), "/")
+				return svc, filepath.Join("testdata", "app.log"), expectedKey
+			},
+		},
+		"file not in any backup directory": {
+			setup: func(t *testing.T) (*Service, string, string) {
+				dir := t.TempDir()
+				svc := &Service{
+					backupDirs: []string{dir},
+				}
+				return svc, "/completely/different/path/file.txt", ""
+			},
+			wantErr:    true,
+			errContain: "does not belong to any configured backup directory",
+		},
+		"empty backup directories": {
+			setup: func(_ *testing.T) (*Service, string, string) {
+				svc := &Service{
+					backupDirs: []string{},
+				}
+				return svc, "/some/file.txt", ""
+			},
+			wantErr:    true,
+			errContain: "does not belong to any configured backup directory",
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, filePath, expectedKey := tc.setup(t)
+			key, err := svc.buildS3Key(svc.snapshotTarget(), filePath)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContain)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, expectedKey, key)
+		})
+	}
+}
+
+// mockS3Client is a simple mock for testing without actual AWS calls.
 type mockS3Client struct {
 	shouldFail bool
+	// failKey makes PutObject fail for the keys it returns true for
+	failKey          func(key string) bool
+	requestID        string
+	versioningStatus types.BucketVersioningStatus
+
+	// objects are the keys returned by ListObjectsV2, objectSizes their sizes if not 0
+	objects     []string
+	objectSizes map[string]int64
+
+	// bucketTags are the tags returned by GetBucketTagging; nil makes it fail with NoSuchTagSet
+	bucketTags []types.Tag
+
+	mu              sync.Mutex
+	headBucketCalls int
+	putKeys         []string
+	putInputs       []*s3.PutObjectInput
+	putSizes        []int64
+	listInputs      []*s3.ListObjectsV2Input
+	partSizes       []int64
+	deleted         [][]string
+	tieringInputs   []*s3.PutBucketIntelligentTieringConfigurationInput
+	taggingInputs   []*s3.PutBucketTaggingInput
 }
 
 var errMockS3Failure = errors.New("mock S3 failure")
 
 func (m *mockS3Client) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
-	if m.shouldFail {
-		return nil, errMockS3Failure
+	if m.shouldFail || (m.failKey != nil && m.failKey(*params.Key)) {
+		return nil, m.failure()
 	}
 
 	// Consume the body to simulate reading the file
+	var size int64
 	if params.Body != nil {
-		_, _ = io.Copy(io.Discard, params.Body)
+		size, _ = io.Copy(io.Discard, params.Body)
 	}
 
+	m.mu.Lock()
+	m.putKeys = append(m.putKeys, *params.Key)
+	m.putInputs = append(m.putInputs, params)
+	m.putSizes = append(m.putSizes, size)
+	m.mu.Unlock()
+
 	return &s3.PutObjectOutput{}, nil
 }
 
+// failure returns errMockS3Failure, wrapped in an AWS response error carrying requestID if one is set.
+func (m *mockS3Client) failure() error {
+	if m.requestID == "" {
+		return errMockS3Failure
+	}
+
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusInternalServerError}},
+			Err:      errMockS3Failure,
+		},
+		RequestID: m.requestID,
+	}
+}
+
+func (m *mockS3Client) HeadBucket(_ context.Context, _ *s3.HeadBucketInput, _ ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	m.mu.Lock()
+	m.headBucketCalls++
+	m.mu.Unlock()
+
+	if m.shouldFail {
+		return nil, m.failure()
+	}
+
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (m *mockS3Client) GetBucketVersioning(_ context.Context, _ *s3.GetBucketVersioningInput, _ ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	if m.shouldFail {
+		return nil, errMockS3Failure
+	}
+
+	return &s3.GetBucketVersioningOutput{Status: m.versioningStatus}, nil
+}
+
+func (m *mockS3Client) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if m.shouldFail {
+		return nil, m.failure()
+	}
+
+	m.mu.Lock()
+	m.listInputs = append(m.listInputs, params)
+	m.mu.Unlock()
+
+	out := &s3.ListObjectsV2Output{}
+	seen := make(map[string]bool)
+	for _, key := range m.objects {
+		if !strings.HasPrefix(key, aws.ToString(params.Prefix)) {
+			continue
+		}
+
+		// Group keys sharing a prefix up to the delimiter, like S3 does
+		rest := strings.TrimPrefix(key, aws.ToString(params.Prefix))
+		if delimiter := aws.ToString(params.Delimiter); delimiter != "" {
+			if i := strings.Index(rest, delimiter); i >= 0 {
+				prefix := aws.ToString(params.Prefix) + rest[:i+len(delimiter)]
+				if !seen[prefix] {
+					seen[prefix] = true
+					out.CommonPrefixes = append(out.CommonPrefixes, types.CommonPrefix{Prefix: aws.String(prefix)})
+				}
+				continue
+			}
+		}
+
+		out.Contents = append(out.Contents, types.Object{Key: aws.String(key), Size: aws.Int64(m.objectSizes[key])})
+	}
+
+	return out, nil
+}
+
+func (m *mockS3Client) DeleteObjects(_ context.Context, params *s3.DeleteObjectsInput, _ ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	if m.shouldFail {
+		return nil, m.failure()
+	}
+
+	keys := make([]string, len(params.Delete.Objects))
+	for i, object := range params.Delete.Objects {
+		keys[i] = aws.ToString(object.Key)
+	}
+
+	m.mu.Lock()
+	m.deleted = append(m.deleted, keys)
+	m.mu.Unlock()
+
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func (m *mockS3Client) PutBucketIntelligentTieringConfiguration(_ context.Context, params *s3.PutBucketIntelligentTieringConfigurationInput, _ ...func(*s3.Options)) (*s3.PutBucketIntelligentTieringConfigurationOutput, error) {
+	if m.shouldFail {
+		return nil, m.failure()
+	}
+
+	m.mu.Lock()
+	m.tieringInputs = append(m.tieringInputs, params)
+	m.mu.Unlock()
+
+	return &s3.PutBucketIntelligentTieringConfigurationOutput{}, nil
+}
+
+func (m *mockS3Client) GetBucketTagging(context.Context, *s3.GetBucketTaggingInput, ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	if m.shouldFail {
+		return nil, m.failure()
+	}
+	if m.bucketTags == nil {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchTagSet", Message: "The TagSet does not exist"}
+	}
+
+	return &s3.GetBucketTaggingOutput{TagSet: m.bucketTags}, nil
+}
+
+func (m *mockS3Client) PutBucketTagging(_ context.Context, params *s3.PutBucketTaggingInput, _ ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+	if m.shouldFail {
+		return nil, m.failure()
+	}
+
+	m.mu.Lock()
+	m.taggingInputs = append(m.taggingInputs, params)
+	m.mu.Unlock()
+
+	return &s3.PutBucketTaggingOutput{}, nil
+}
+
+func (m *mockS3Client) CreateMultipartUpload(_ context.Context, _ *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	if m.shouldFail {
+		return nil, m.failure()
+	}
+
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("mock-upload-id")}, nil
+}
+
+func (m *mockS3Client) UploadPart(_ context.Context, params *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	n, err := io.Copy(io.Discard, params.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.partSizes = append(m.partSizes, n)
+	m.mu.Unlock()
+
+	return &s3.UploadPartOutput{ETag: aws.String("mock-etag")}, nil
+}
+
+func (m *mockS3Client) CompleteMultipartUpload(_ context.Context, params *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	m.mu.Lock()
+	m.putKeys = append(m.putKeys, *params.Key)
+	m.mu.Unlock()
+
+	return &s3.CompleteMultipartUploadOutput{Key: params.Key}, nil
+}
+
+func (m *mockS3Client) AbortMultipartUpload(_ context.Context, _ *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func TestService_BucketVersioningStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		client  *mockS3Client
+		want    string
+		wantErr error
+	}{
+		"versioning enabled": {
+			client: &mockS3Client{versioningStatus: types.BucketVersioningStatusEnabled},
+			want:   "Enabled",
+		},
+		"versioning suspended": {
+			client: &mockS3Client{versioningStatus: types.BucketVersioningStatusSuspended},
+			want:   "Suspended",
+		},
+		"versioning never enabled": {
+			client: &mockS3Client{},
+			want:   "",
+		},
+		"S3 call fails": {
+			client:  &mockS3Client{shouldFail: true},
+			wantErr: errMockS3Failure,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{client: tc.client, bucketName: "test-bucket"}
+			status, err := svc.BucketVersioningStatus(ctx)
+
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, status)
+		})
+	}
+}
+
+func TestService_CheckBucketVersioning(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tc := map[string]struct {
+		svc     *Service
+		wantErr error
+	}{
+		"check disabled skips S3 call": {
+			svc: &Service{client: &mockS3Client{shouldFail: true}},
+		},
+		"versioning enabled": {
+			svc: &Service{
+				client:          &mockS3Client{versioningStatus: types.BucketVersioningStatusEnabled},
+				versioningCheck: true,
+			},
+		},
+		"versioning suspended only warns": {
+			svc: &Service{
+				client:          &mockS3Client{versioningStatus: types.BucketVersioningStatusSuspended},
+				versioningCheck: true,
+			},
+		},
+		"versioning never enabled only warns": {
+			svc: &Service{
+				client:          &mockS3Client{},
+				versioningCheck: true,
+			},
+		},
+		"versioning suspended when required": {
+			svc: &Service{
+				client:            &mockS3Client{versioningStatus: types.BucketVersioningStatusSuspended},
+				versioningCheck:   true,
+				requireVersioning: true,
+			},
+			wantErr: ErrVersioningNotEnabled,
+		},
+		"versioning never enabled when required": {
+			svc: &Service{
+				client:            &mockS3Client{},
+				versioningCheck:   true,
+				requireVersioning: true,
+			},
+			wantErr: ErrVersioningNotEnabled,
+		},
+		"S3 call fails": {
+			svc: &Service{
+				client:          &mockS3Client{shouldFail: true},
+				versioningCheck: true,
+			},
+			wantErr: errMockS3Failure,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.svc.bucketName = "test-bucket"
+			err := tc.svc.CheckBucketVersioning(ctx)
+
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestService_Start(t *testing.T) {
 	t.Parallel()
 
@@ -561,6 +1876,25 @@ func TestService_Start(t *testing.T) {
 			cronSchedule: "0 0 * * *",
 			wantErr:      false,
 		},
+		"daily alias": {
+			cronSchedule: "@daily",
+		},
+		"hourly alias": {
+			cronSchedule: "@hourly",
+		},
+		"weekly alias": {
+			cronSchedule: "@weekly",
+		},
+		"monthly alias": {
+			cronSchedule: "@monthly",
+		},
+		"every interval": {
+			cronSchedule: "@every 6h",
+		},
+		"reboot alias is not supported": {
+			cronSchedule: "@reboot",
+			wantErr:      true,
+		},
 		"empty cron schedule": {
 			cronSchedule: "",
 			wantErr:      true,
@@ -610,7 +1944,7 @@ func TestService_Start(t *testing.T) {
 					t.Error("Expected error but Start() is still running")
 				}
 				// Stop the service
-				svc.Stop()
+				assert.True(t, svc.Stop())
 				// Wait for it to actually stop
 				select {
 				case err := <-errCh:
@@ -647,7 +1981,8 @@ func TestService_Stop(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 
 	// Stop should close the channel and cause Start to return
-	svc.Stop()
+	assert.True(t, svc.Stop())
+	assert.False(t, svc.Stop())
 
 	select {
 	case err := <-errCh:
@@ -657,6 +1992,48 @@ func TestService_Stop(t *testing.T) {
 	}
 }
 
+func TestService_Close(t *testing.T) {
+	t.Parallel()
+
+	cacheFile := filepath.Join(t.TempDir(), "cache.db")
+	fileCache, err := cache.Load(cacheFile)
+	require.NoError(t, err)
+
+	svc := &Service{cache: fileCache, stopCh: make(chan struct{})}
+
+	require.NoError(t, svc.Close())
+	require.NoError(t, svc.Close(), "Close must be idempotent")
+
+	assert.False(t, svc.Stop(), "Close must stop the service")
+	assert.FileExists(t, cacheFile, "Close must save the cache")
+}
+
+func TestService_Close_WaitsForScheduledBackup(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{stopCh: make(chan struct{})}
+	svc.scheduledRunSlots() <- struct{}{}
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- svc.Close()
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("Close returned while a scheduled backup was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-svc.scheduledRunSlots()
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return after the scheduled backup finished")
+	}
+}
+
 // createTestConfig creates a test config with temporary directories.
 func createTestConfig(t *testing.T, dirCount int, recursive bool) *config.Config {
 	t.Helper()
@@ -668,6 +2045,17 @@ func createTestConfig(t *testing.T, dirCount int, recursive bool) *config.Config
 	}
 }
 
+// testServerOptions points the S3 client at a test server using path-style,
+// unsigned requests without retries.
+func testServerOptions(url string) func(*s3.Options) {
+	return func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(url)
+		o.UsePathStyle = true
+		o.Credentials = aws.AnonymousCredentials{}
+		o.Retryer = aws.NopRetryer{}
+	}
+}
+
 // createTempDirs creates multiple temporary directories for testing.
 func createTempDirs(t *testing.T, count int) []string {
 	t.Helper()
@@ -677,3 +2065,70 @@ func createTempDirs(t *testing.T, count int) []string {
 	}
 	return dirs
 }
+
+// logRecorder is a slog.Handler that records every log record for later assertions.
+type logRecorder struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *logRecorder) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *logRecorder) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+func (h *logRecorder) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *logRecorder) WithGroup(string) slog.Handler { return h }
+
+// find returns the first recorded log record with the given message.
+func (h *logRecorder) find(msg string) (slog.Record, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range h.records {
+		if r.Message == msg {
+			return r, true
+		}
+	}
+	return slog.Record{}, false
+}
+
+// findAll returns every recorded log record with the given message, in the order they were logged.
+func (h *logRecorder) findAll(msg string) []slog.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var found []slog.Record
+	for _, r := range h.records {
+		if r.Message == msg {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// captureLogs replaces the default logger with a recorder for the duration of the test.
+// Tests using it must not run in parallel.
+func captureLogs(t *testing.T) *logRecorder {
+	t.Helper()
+	rec := &logRecorder{}
+	prev := slog.Default()
+	slog.SetDefault(slog.New(rec))
+	t.Cleanup(func() {
+		slog.SetDefault(prev)
+	})
+	return rec
+}
+
+// recordAttrs returns the attributes of a log record as a map of key to resolved value.
+func recordAttrs(r slog.Record) map[string]any {
+	attrs := make(map[string]any, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.Resolve().Any()
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,76 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// BackupSession summarizes the objects uploaded by one backup, identified by its timestamp prefix.
+type BackupSession struct {
+	Timestamp  time.Time `json:"timestamp"`
+	FileCount  int64     `json:"file_count"`
+	TotalBytes int64     `json:"total_bytes"`
+}
+
+// ListBackupSessions returns the backups in the bucket that started at or after since, oldest
+// first. Prefixes that are not backup timestamps are ignored. Every session is listed in full
+// to count its objects, so this makes at least one request per session.
+func (s *Service) ListBackupSessions(ctx context.Context, since time.Time) ([]BackupSession, error) {
+	const op = "s3.Service.ListBackupSessions"
+
+	prefixes, err := s.listBackupPrefixes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessions := make([]BackupSession, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		ts, last, err := parseBackupPrefix(strings.TrimSuffix(prefix, s.keySeparator()))
+		if err != nil || last.Before(since) {
+			continue
+		}
+
+		session, err := s.summarizePrefix(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		session.Timestamp = ts
+		sessions = append(sessions, session)
+	}
+
+	slices.SortFunc(sessions, func(a, b BackupSession) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return sessions, nil
+}
+
+// summarizePrefix returns the number and total size of the objects under prefix.
+func (s *Service) summarizePrefix(ctx context.Context, prefix string) (BackupSession, error) {
+	const op = "s3.Service.summarizePrefix"
+
+	var session BackupSession
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:       &s.bucketName,
+		Prefix:       &prefix,
+		RequestPayer: s.requestPayer(),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return BackupSession{}, fmt.Errorf("%s: failed to list objects (prefix=%s): %w", op, prefix, err)
+		}
+
+		for _, object := range page.Contents {
+			session.FileCount++
+			session.TotalBytes += aws.ToInt64(object.Size)
+		}
+	}
+
+	return session, nil
+}
@@ -0,0 +1,155 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListBackupSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	older := now.AddDate(0, 0, -30)
+	old := now.AddDate(0, 0, -3)
+	recent := now.Add(-time.Hour)
+
+	objects := []string{
+		older.Format(timestampLayout) + "/docs/a.txt",
+		old.Format(timestampLayout) + "/docs/a.txt",
+		old.Format(timestampLayout) + "/docs/nested/b.txt",
+		recent.Format(timestampLayout) + "/docs/a.txt",
+		"manual/notes.txt",
+		"_latest/pointer.json",
+	}
+	sizes := map[string]int64{objects[0]: 1, objects[1]: 10, objects[2]: 20, objects[3]: 5, objects[4]: 100}
+
+	tc := map[string]struct {
+		since time.Time
+		want  []BackupSession
+	}{
+		"all sessions oldest first": {
+			want: []BackupSession{
+				{Timestamp: older, FileCount: 1, TotalBytes: 1},
+				{Timestamp: old, FileCount: 2, TotalBytes: 30},
+				{Timestamp: recent, FileCount: 1, TotalBytes: 5},
+			},
+		},
+		"sessions since a week ago": {
+			since: now.AddDate(0, 0, -7),
+			want: []BackupSession{
+				{Timestamp: old, FileCount: 2, TotalBytes: 30},
+				{Timestamp: recent, FileCount: 1, TotalBytes: 5},
+			},
+		},
+		"since is inclusive": {
+			since: recent,
+			want:  []BackupSession{{Timestamp: recent, FileCount: 1, TotalBytes: 5}},
+		},
+		"no sessions since now": {
+			since: now.Add(time.Minute),
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockS3Client{objects: objects, objectSizes: sizes}
+			svc := &Service{client: client, bucketName: "test-bucket"}
+
+			got, err := svc.ListBackupSessions(ctx, tc.since)
+			require.NoError(t, err)
+			require.Len(t, got, len(tc.want))
+			for i := range tc.want {
+				assert.True(t, tc.want[i].Timestamp.Equal(got[i].Timestamp), "session %d: got %v", i, got[i].Timestamp)
+				assert.Equal(t, tc.want[i].FileCount, got[i].FileCount)
+				assert.Equal(t, tc.want[i].TotalBytes, got[i].TotalBytes)
+			}
+		})
+	}
+}
+
+func TestService_ListBackupSessions_Error(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{client: &mockS3Client{shouldFail: true}, bucketName: "test-bucket"}
+
+	_, err := svc.ListBackupSessions(context.Background(), time.Time{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errMockS3Failure)
+}
+
+func TestService_ListBackupSessions_KeySeparator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	require.NoError(t, os.Mkdir(docs, 0750))
+	createFile(t, docs, "file.txt", "content")
+
+	client := &mockS3Client{}
+	svc := &Service{
+		client:             client,
+		bucketName:         "test-bucket",
+		backupDirs:         []string{docs},
+		objectKeySeparator: "_",
+		nowFunc:            func() time.Time { return now },
+	}
+	require.NoError(t, svc.Backup(ctx))
+	assert.Equal(t, []string{"2025-06-01T12-00-00_docs_file.txt"}, client.putKeys)
+
+	// Backups are found by the separator after their timestamp
+	client.objects = client.putKeys
+	got, err := svc.ListBackupSessions(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, now.Equal(got[0].Timestamp))
+	assert.Equal(t, int64(1), got[0].FileCount)
+}
+
+func TestService_Backup_PrefixDateOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	morning := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	evening := time.Date(2025, 6, 1, 18, 0, 0, 0, time.Local)
+
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	require.NoError(t, os.Mkdir(docs, 0750))
+	createFile(t, docs, "file.txt", "content")
+
+	client := &mockS3Client{}
+	now := morning
+	svc := &Service{
+		client:         client,
+		bucketName:     "test-bucket",
+		backupDirs:     []string{docs},
+		prefixDateOnly: true,
+		nowFunc:        func() time.Time { return now },
+	}
+	require.NoError(t, svc.Backup(ctx))
+	now = evening
+	require.NoError(t, svc.Backup(ctx))
+
+	// Both backups of the day upload to the same key, without a time component
+	assert.Equal(t, []string{"2025-06-01/docs/file.txt", "2025-06-01/docs/file.txt"}, client.putKeys)
+
+	// The day is listed as one session, also since a time later that day
+	client.objects = client.putKeys[:1]
+	got, err := svc.ListBackupSessions(ctx, evening)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local).Equal(got[0].Timestamp))
+	assert.Equal(t, int64(1), got[0].FileCount)
+}
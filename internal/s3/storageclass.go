@@ -0,0 +1,21 @@
+package s3
+
+import (
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// applyStorageClass sets the storage class of input to the one mapped to the extension of
+// name, matched case-insensitively. Objects of other extensions keep the default storage
+// class of the bucket.
+func (s *Service) applyStorageClass(input *s3.PutObjectInput, name string) {
+	class, ok := s.storageClassMap[strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))]
+	if !ok {
+		return
+	}
+
+	input.StorageClass = types.StorageClass(class)
+}
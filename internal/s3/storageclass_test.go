@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BackupFile_StorageClassMap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	classes := map[string]string{"log": "GLACIER_IR", "sql": "STANDARD"}
+
+	tc := map[string]struct {
+		fileName  string
+		classes   map[string]string
+		wantClass types.StorageClass
+	}{
+		"log file":              {fileName: "app.log", classes: classes, wantClass: types.StorageClassGlacierIr},
+		"sql file":              {fileName: "dump.sql", classes: classes, wantClass: types.StorageClassStandard},
+		"upper-case extension":  {fileName: "APP.LOG", classes: classes, wantClass: types.StorageClassGlacierIr},
+		"unmapped extension":    {fileName: "notes.txt", classes: classes},
+		"no extension":          {fileName: "Makefile", classes: classes},
+		"no storage class map":  {fileName: "app.log"},
+		"last extension counts": {fileName: "dump.sql.log", classes: classes, wantClass: types.StorageClassGlacierIr},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			createFile(t, dir, tc.fileName, "content")
+
+			client := &mockS3Client{}
+			svc := &Service{
+				client:          client,
+				bucketName:      "test-bucket",
+				backupDirs:      []string{dir},
+				storageClassMap: tc.classes,
+			}
+
+			require.NoError(t, svc.backupFile(ctx, svc.snapshotTarget(), filepath.Join(dir, tc.fileName), time.Now()))
+			require.Len(t, client.putInputs, 1)
+			assert.Equal(t, tc.wantClass, client.putInputs[0].StorageClass)
+		})
+	}
+}
+
+func TestNewS3Service_StorageClassMap(t *testing.T) {
+	t.Parallel()
+
+	cfg := createTestConfig(t, 1, false)
+	cfg.StorageClassMap = map[string]string{".LOG": "glacier_ir"}
+
+	svc, err := NewS3Service(context.Background(), cfg, WithClient(&mockS3Client{}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"log": "GLACIER_IR"}, svc.storageClassMap)
+}
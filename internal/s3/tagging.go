@@ -0,0 +1,90 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// Tags set on the bucket by TagBucket.
+const (
+	tagManagedBy = "managed-by"
+	tagVersion   = "version"
+	tagCreated   = "created"
+
+	managedByValue = "s3-backup"
+	defaultVersion = "dev"
+)
+
+// TagBucket tags the bucket with managed-by=s3-backup, the s3-backup version, and the time it
+// was first tagged. Existing tags of the bucket are kept, since PutBucketTagging replaces the
+// whole tag set, and the created tag is only set once. It is a no-op unless bucket tagging is
+// enabled.
+func (s *Service) TagBucket(ctx context.Context) error {
+	const op = "s3.Service.TagBucket"
+
+	if !s.tagBucket {
+		return nil
+	}
+
+	tags, err := s.bucketTags(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	version := s.version
+	if version == "" {
+		version = defaultVersion
+	}
+	next := maps.Clone(tags)
+	next[tagManagedBy] = managedByValue
+	next[tagVersion] = version
+	if _, ok := next[tagCreated]; !ok {
+		next[tagCreated] = s.now().UTC().Format(time.RFC3339)
+	}
+	if maps.Equal(tags, next) {
+		s.logger().Debug("bucket tags are up to date", "bucket", s.bucketName)
+		return nil
+	}
+
+	tagSet := make([]types.Tag, 0, len(next))
+	for _, key := range slices.Sorted(maps.Keys(next)) {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(key), Value: aws.String(next[key])})
+	}
+	if _, err := s.client.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(s.bucketName),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	}); err != nil {
+		return fmt.Errorf("%s: failed to tag bucket %s: %w", op, s.bucketName, err)
+	}
+
+	s.logger().Info("tagged bucket", "bucket", s.bucketName, "version", version)
+	return nil
+}
+
+// bucketTags returns the tags of the bucket keyed by their key. A bucket without tags, for
+// which S3 returns a NoSuchTagSet error, has an empty map.
+func (s *Service) bucketTags(ctx context.Context) (map[string]string, error) {
+	out, err := s.client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(s.bucketName)})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchTagSet" {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to get tags of bucket %s: %w", s.bucketName, err)
+	}
+
+	tags := make(map[string]string, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
@@ -0,0 +1,104 @@
+package s3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_TagBucket(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+	tag := func(key, value string) types.Tag {
+		return types.Tag{Key: aws.String(key), Value: aws.String(value)}
+	}
+
+	tc := map[string]struct {
+		tagBucket  bool
+		version    string
+		bucketTags []types.Tag
+		shouldFail bool
+		wantErr    bool
+		wantTags   []types.Tag
+	}{
+		"disabled": {},
+		"bucket without tags": {
+			tagBucket: true,
+			version:   "v1.4.0",
+			wantTags: []types.Tag{
+				tag("created", "2025-12-15T14:30:00Z"),
+				tag("managed-by", "s3-backup"),
+				tag("version", "v1.4.0"),
+			},
+		},
+		"existing tags are kept": {
+			tagBucket:  true,
+			version:    "v1.4.0",
+			bucketTags: []types.Tag{tag("team", "platform"), tag("created", "2024-01-01T00:00:00Z"), tag("version", "v1.3.0")},
+			wantTags: []types.Tag{
+				tag("created", "2024-01-01T00:00:00Z"),
+				tag("managed-by", "s3-backup"),
+				tag("team", "platform"),
+				tag("version", "v1.4.0"),
+			},
+		},
+		"version defaults to dev": {
+			tagBucket: true,
+			wantTags: []types.Tag{
+				tag("created", "2025-12-15T14:30:00Z"),
+				tag("managed-by", "s3-backup"),
+				tag("version", "dev"),
+			},
+		},
+		"tags up to date": {
+			tagBucket:  true,
+			version:    "v1.4.0",
+			bucketTags: []types.Tag{tag("created", "2024-01-01T00:00:00Z"), tag("managed-by", "s3-backup"), tag("version", "v1.4.0")},
+		},
+		"request fails": {
+			tagBucket:  true,
+			shouldFail: true,
+			wantErr:    true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockS3Client{bucketTags: tc.bucketTags, shouldFail: tc.shouldFail}
+			svc := &Service{
+				client:     client,
+				bucketName: "test-bucket",
+				tagBucket:  tc.tagBucket,
+				version:    tc.version,
+				nowFunc:    func() time.Time { return now },
+			}
+
+			err := svc.TagBucket(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errMockS3Failure)
+				assert.Contains(t, err.Error(), "test-bucket")
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.wantTags == nil {
+				assert.Empty(t, client.taggingInputs)
+				return
+			}
+
+			require.Len(t, client.taggingInputs, 1)
+			input := client.taggingInputs[0]
+			assert.Equal(t, "test-bucket", aws.ToString(input.Bucket))
+			assert.Equal(t, tc.wantTags, input.Tagging.TagSet)
+		})
+	}
+}
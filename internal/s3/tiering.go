@@ -0,0 +1,54 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// intelligentTieringConfigID is the ID of the bucket Intelligent-Tiering configuration
+// managed by ConfigureBucket, so that other configurations of the bucket are left alone.
+const intelligentTieringConfigID = "s3-backup"
+
+// ConfigureBucket creates or updates the Intelligent-Tiering configuration of the bucket, which
+// moves objects in the Intelligent-Tiering storage class to the Archive Access and Deep Archive
+// Access tiers after the configured days without access. It applies to the whole bucket and is
+// a no-op unless configuring intelligent tiering is enabled.
+func (s *Service) ConfigureBucket(ctx context.Context) error {
+	const op = "s3.Service.ConfigureBucket"
+
+	if !s.configureTiering {
+		return nil
+	}
+
+	_, err := s.client.PutBucketIntelligentTieringConfiguration(ctx, &s3.PutBucketIntelligentTieringConfigurationInput{
+		Bucket: aws.String(s.bucketName),
+		Id:     aws.String(intelligentTieringConfigID),
+		IntelligentTieringConfiguration: &types.IntelligentTieringConfiguration{
+			Id:     aws.String(intelligentTieringConfigID),
+			Status: types.IntelligentTieringStatusEnabled,
+			Tierings: []types.Tiering{
+				{
+					AccessTier: types.IntelligentTieringAccessTierArchiveAccess,
+					Days:       aws.Int32(int32(s.tieringArchiveDays)), //nolint:gosec // G115: validated to at most 730
+				},
+				{
+					AccessTier: types.IntelligentTieringAccessTierDeepArchiveAccess,
+					Days:       aws.Int32(int32(s.tieringDeepArchiveDays)), //nolint:gosec // G115: validated to at most 730
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: failed to configure intelligent tiering of bucket %s: %w", op, s.bucketName, err)
+	}
+
+	s.logger().Info("configured bucket intelligent tiering",
+		"bucket", s.bucketName,
+		"archive_access_days", s.tieringArchiveDays,
+		"deep_archive_access_days", s.tieringDeepArchiveDays)
+	return nil
+}
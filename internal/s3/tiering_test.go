@@ -0,0 +1,72 @@
+package s3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ConfigureBucket(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		configureTiering bool
+		shouldFail       bool
+		wantErr          bool
+		wantCalls        int
+	}{
+		"disabled": {},
+		"enabled": {
+			configureTiering: true,
+			wantCalls:        1,
+		},
+		"request fails": {
+			configureTiering: true,
+			shouldFail:       true,
+			wantErr:          true,
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockS3Client{shouldFail: tc.shouldFail}
+			svc := &Service{
+				client:                 client,
+				bucketName:             "test-bucket",
+				configureTiering:       tc.configureTiering,
+				tieringArchiveDays:     120,
+				tieringDeepArchiveDays: 365,
+			}
+
+			err := svc.ConfigureBucket(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errMockS3Failure)
+				assert.Contains(t, err.Error(), "test-bucket")
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, client.tieringInputs, tc.wantCalls)
+			if tc.wantCalls == 0 {
+				return
+			}
+
+			input := client.tieringInputs[0]
+			assert.Equal(t, "test-bucket", aws.ToString(input.Bucket))
+			assert.Equal(t, intelligentTieringConfigID, aws.ToString(input.Id))
+			assert.Equal(t, intelligentTieringConfigID, aws.ToString(input.IntelligentTieringConfiguration.Id))
+			assert.Equal(t, types.IntelligentTieringStatusEnabled, input.IntelligentTieringConfiguration.Status)
+			assert.Equal(t, []types.Tiering{
+				{AccessTier: types.IntelligentTieringAccessTierArchiveAccess, Days: aws.Int32(120)},
+				{AccessTier: types.IntelligentTieringAccessTierDeepArchiveAccess, Days: aws.Int32(365)},
+			}, input.IntelligentTieringConfiguration.Tierings)
+		})
+	}
+}
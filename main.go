@@ -3,16 +3,50 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"s3-backup/internal/config"
+	applog "s3-backup/internal/log"
 	"s3-backup/internal/s3"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"syscall"
+	"text/tabwriter"
+	"time"
 )
 
-func init() {
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
+const (
+	// outputTable prints command results as a human-readable table.
+	outputTable = "table"
+
+	// outputJSON prints command results as a JSON document.
+	outputJSON = "json"
+
+	// outputHuman is accepted as another name for outputTable.
+	outputHuman = "human"
+)
+
+// Version is the version of the build, set with -ldflags "-X main.Version=v1.2.3".
+var Version = "dev"
+
+// cliOptions holds the parsed command line flags.
+type cliOptions struct {
+	configFile   string
+	dryRun       bool
+	estimate     bool
+	listSessions bool
+	since        time.Duration
+	nextRuns     int
+	output       string
 }
 
 func main() {
@@ -20,17 +54,74 @@ func main() {
 }
 
 func run() int {
+	opts, err := parseFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	// Keep stdout clean for machine-readable output
+	logOutput := io.Writer(os.Stdout)
+	if opts.output == outputJSON {
+		logOutput = os.Stderr
+	}
+	logOutput, closeLogFile, err := mirrorToLogFile(logOutput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer closeLogFile()
+	if err := setupLogger(logOutput); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := applyConfigFile(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
 	// Create context that cancels on interrupt signals
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Setup signal handling for graceful shutdown
+	// Setup signal handling for graceful shutdown. Shutdown signals cancel ctx right away, so a
+	// hung startup can be interrupted; stopping the scheduler and reloading wait for the service.
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	// service and loadedCfg are set once the service is created
+	var service atomic.Pointer[s3.Service]
+	var loadedCfg atomic.Pointer[config.Config]
 	go func() {
-		sig := <-sigCh
-		slog.Info("received shutdown signal", "signal", sig)
-		cancel()
+		var current *config.Config
+		shuttingDown := false
+		for sig := range sigCh {
+			svc := service.Load()
+			if sig == syscall.SIGHUP {
+				if svc == nil {
+					slog.Warn("ignoring reload signal during startup", "signal", sig)
+					continue
+				}
+				if current == nil {
+					current = loadedCfg.Load()
+				}
+				current = reloadConfig(svc, current)
+				continue
+			}
+
+			cancel()
+			if svc != nil {
+				svc.Stop()
+			}
+			// Only the first signal starts the shutdown
+			if !shuttingDown {
+				shuttingDown = true
+				slog.Info("received shutdown signal", "signal", sig)
+			}
+		}
 	}()
 
 	cfg, err := config.NewConfig()
@@ -39,16 +130,55 @@ func run() int {
 		return 1
 	}
 
-	slog.Info("configuration loaded successfully",
-		"aws_region", cfg.GetAWSRegion(),
-		"s3_bucket", cfg.GetS3Bucket(),
-		"cron_schedule", cfg.GetCronSchedule())
+	slog.Info("configuration loaded successfully", "config", cfg.String())
+	printStartupBanner(cfg)
 
-	s3Service, err := s3.NewS3Service(ctx, cfg)
+	s3Service, err := s3.NewS3Service(ctx, cfg, s3.WithVersion(Version))
 	if err != nil {
 		slog.Error("failed to create S3 service", "error", err)
 		return 1
 	}
+	defer func() {
+		if err := s3Service.Close(); err != nil {
+			slog.Error("failed to close S3 service", "error", err)
+		}
+	}()
+
+	loadedCfg.Store(cfg)
+	service.Store(s3Service)
+	// A shutdown signal received during startup only cancelled ctx
+	if ctx.Err() != nil {
+		s3Service.Stop()
+	}
+
+	if opts.nextRuns > 0 {
+		return runNextRuns(s3Service, opts.nextRuns)
+	}
+
+	// Dry runs and estimates only inspect local files, so they run before any S3 calls
+	if opts.dryRun {
+		return runDryRun(ctx, s3Service, opts.output)
+	}
+	if opts.estimate {
+		return runEstimate(ctx, s3Service, opts.output)
+	}
+
+	if opts.listSessions {
+		return runListSessions(ctx, s3Service, opts)
+	}
+
+	if err := s3Service.CheckBucketVersioning(ctx); err != nil {
+		slog.Error("bucket versioning check failed", "error", err)
+		return 1
+	}
+	if err := s3Service.ConfigureBucket(ctx); err != nil {
+		slog.Error("bucket configuration failed", "error", err)
+		return 1
+	}
+	if err := s3Service.TagBucket(ctx); err != nil {
+		slog.Error("bucket tagging failed", "error", err)
+		return 1
+	}
 
 	// Check if cron schedule is configured
 	if cfg.GetCronSchedule() != "" {
@@ -60,12 +190,478 @@ func run() int {
 		slog.Info("scheduler stopped gracefully")
 		return 0
 	}
+	if cfg.IsCronScheduleSet() {
+		slog.Warn("cron schedule is set but empty, running one-time backup", "env", config.EnvCronSchedule)
+	}
+
 	// One-time backup
 	slog.Info("running one-time backup")
-	if err := s3Service.Backup(ctx); err != nil {
+	stats, err := s3Service.BackupWithStats(ctx)
+	if opts.output == outputJSON {
+		if printErr := printBackupResult(os.Stdout, stats, err); printErr != nil {
+			slog.Error("failed to print backup result", "error", printErr)
+			return 1
+		}
+	}
+	if err != nil {
 		slog.Error("backup failed", "error", err)
 		return 1
 	}
+
 	slog.Info("backup completed successfully")
 	return 0
 }
+
+// printStartupBanner logs the version and the optional features in use in a single line, so the
+// start of every run shows how it is configured. The KMS key ID is masked.
+func printStartupBanner(cfg *config.Config) {
+	schedule := cfg.GetCronSchedule()
+	if schedule == "" {
+		schedule = "one-time"
+	}
+	archiveMode := cfg.GetArchiveMode()
+	if archiveMode == "" {
+		archiveMode = "none"
+	}
+	checksum := cfg.GetChecksumAlgorithm()
+	if checksum == "" {
+		checksum = "none"
+	}
+	encryption := "bucket-default"
+	if cfg.GetKMSKeyID() != "" {
+		encryption = "aws:kms"
+	}
+	objectLock := cfg.GetObjectLockMode()
+	if objectLock == "" {
+		objectLock = "none"
+	}
+
+	slog.Info("starting s3-backup",
+		"version", Version,
+		"backup_dirs", len(cfg.GetBackupDirs()),
+		"schedule", schedule,
+		"recursive", cfg.IsRecursive(),
+		"max_walk_depth", cfg.GetMaxWalkDepth(),
+		"exclude_hidden", cfg.IsExcludeHidden(),
+		"skip_locked_files", cfg.IsSkipLockedFiles(),
+		"skip_unchanged_dirs", cfg.IsSkipUnchangedDirs(),
+		"incremental", cfg.IsIncremental(),
+		"archive_mode", archiveMode,
+		"concurrency", cfg.GetConcurrency(),
+		"concurrency_per_dir", cfg.GetConcurrencyPerDir(),
+		"multipart_upload", cfg.IsMultipartUpload(),
+		"checksum_algorithm", checksum,
+		"encryption_type", encryption,
+		"kms_key_id", cfg.SafeMap()["kms_key_id"],
+		"object_lock_mode", objectLock,
+		"retention_days", cfg.GetRetentionDays(),
+		"presign_after_upload", cfg.IsPresignAfterUpload(),
+	)
+}
+
+// reloadableFields are the Config fields that Service.ReloadConfig applies without a restart.
+var reloadableFields = []string{"BackupDirs", "Recursive"}
+
+// reloadConfig loads the configuration again and applies it to the service, logging the
+// names of the changed fields. It returns the configuration now in use, which is current
+// if the new configuration could not be loaded or applied.
+func reloadConfig(svc *s3.Service, current *config.Config) *config.Config {
+	// Stdin was consumed by the first load; reading it again would silently drop the YAML settings
+	if os.Getenv(config.EnvConfigFile) == config.ConfigFileStdin {
+		slog.Warn("configuration read from stdin cannot be reloaded, restart to apply changes")
+		return current
+	}
+
+	next, err := config.NewConfig()
+	if err != nil {
+		slog.Error("failed to reload configuration", "error", err)
+		return current
+	}
+
+	if err := svc.ReloadConfig(next); err != nil {
+		slog.Error("failed to reload configuration", "error", err)
+		return current
+	}
+
+	changed := current.Diff(next)
+	slog.Info("configuration reloaded", "changed_fields", changed)
+
+	if restart := slices.DeleteFunc(slices.Clone(changed), func(field string) bool {
+		return slices.Contains(reloadableFields, field)
+	}); len(restart) > 0 {
+		slog.Warn("changed fields take effect after a restart", "fields", restart)
+	}
+
+	return next
+}
+
+// applyConfigFile points config.NewConfig at the file given by --config-file, which takes
+// precedence over S3_BACKUP_CONFIG_FILE. The environment is also read by configuration
+// reloads, so the flag keeps applying after SIGHUP.
+func applyConfigFile(opts *cliOptions) error {
+	if opts.configFile == "" {
+		return nil
+	}
+	if err := os.Setenv(config.EnvConfigFile, opts.configFile); err != nil {
+		return fmt.Errorf("failed to set %s: %w", config.EnvConfigFile, err)
+	}
+	return nil
+}
+
+// setupLogger configures the default logger to write text logs to w. The global level is read
+// from LOG_LEVEL (default INFO) and can be overridden per package, e.g. BACKUP_LOG_LEVEL_S3=DEBUG.
+func setupLogger(w io.Writer) error {
+	level, err := envLogLevel(config.EnvLogLevel, slog.LevelInfo)
+	if err != nil {
+		return err
+	}
+
+	packageLevels := make(map[string]slog.Level)
+	for pkg, key := range map[string]string{
+		"internal/config": config.EnvLogLevelConfig,
+		"internal/s3":     config.EnvLogLevelS3,
+	} {
+		if os.Getenv(key) == "" {
+			continue
+		}
+		if packageLevels[pkg], err = envLogLevel(key, level); err != nil {
+			return err
+		}
+	}
+
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{AddSource: os.Getenv(config.EnvLogSource) == "true"})
+	slog.SetDefault(slog.New(applog.NewLeveledHandler(handler, level, packageLevels)))
+	return nil
+}
+
+// mirrorToLogFile returns a writer that writes to w and, if BACKUP_LOG_FILE is set, also
+// appends to that file, rotating it at BACKUP_LOG_MAX_SIZE_MB and keeping BACKUP_LOG_MAX_BACKUPS
+// rotated files. The returned function closes the file.
+func mirrorToLogFile(w io.Writer) (io.Writer, func(), error) {
+	path := os.Getenv(config.EnvLogFile)
+	if path == "" {
+		return w, func() {}, nil
+	}
+
+	maxSizeMB, err := envInt(config.EnvLogMaxSizeMB, config.DefaultLogMaxSizeMB)
+	if err != nil {
+		return nil, nil, err
+	}
+	maxBackups, err := envInt(config.EnvLogMaxBackups, config.DefaultLogMaxBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := applog.OpenRotatingFile(path, int64(maxSizeMB)<<20, maxBackups)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w (set %s)", err, config.EnvLogFile)
+	}
+
+	return io.MultiWriter(w, file), func() { _ = file.Close() }, nil
+}
+
+// envInt parses the non-negative integer in the environment variable key, returning fallback if it is not set.
+func envInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a non-negative integer", key, value)
+	}
+	return n, nil
+}
+
+// envLogLevel parses the log level in the environment variable key, returning fallback if it is not set.
+func envLogLevel(key string, fallback slog.Level) (slog.Level, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	level, err := applog.ParseLevel(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s (set %s)", err, key)
+	}
+	return level, nil
+}
+
+// parseFlags parses the command line arguments into cliOptions.
+func parseFlags(args []string) (*cliOptions, error) {
+	opts := &cliOptions{}
+
+	fs := flag.NewFlagSet("s3-backup", flag.ContinueOnError)
+	fs.StringVar(&opts.configFile, "config-file", "", "path to the YAML configuration file, or - to read it from stdin; overrides "+config.EnvConfigFile)
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "log the objects a backup would upload without uploading; also written as JSON to "+config.EnvDryRunOutputFile+" if set")
+	fs.BoolVar(&opts.estimate, "estimate", false, "print the number of files, total size, and projected PUT cost of a backup without uploading")
+	fs.BoolVar(&opts.listSessions, "list-sessions", false, "print the backups in the bucket with their file count and size")
+	since := fs.String("since", "", "only list backups started within this period, e.g. 7d or 12h; used with --list-sessions")
+	fs.IntVar(&opts.nextRuns, "next-runs", 0, "print the next N scheduled backup times and exit")
+	fs.StringVar(&opts.output, "output", envOutput(), "output format for --dry-run, --estimate, --list-sessions, and one-time backups: table (or human) or json; defaults to "+config.EnvOutputFormat)
+	fs.Usage = func() { printUsage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *since != "" {
+		d, err := parseSince(*since)
+		if err != nil {
+			err = fmt.Errorf("invalid --since %q: %w", *since, err)
+			_, _ = fmt.Fprintln(fs.Output(), err)
+			fs.Usage()
+			return nil, err
+		}
+		opts.since = d
+	}
+
+	if opts.output == outputHuman {
+		opts.output = outputTable
+	}
+	if opts.output != outputTable && opts.output != outputJSON {
+		err := fmt.Errorf("invalid --output %q: must be %s, %s, or %s", opts.output, outputTable, outputHuman, outputJSON)
+		_, _ = fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		return nil, err
+	}
+
+	return opts, nil
+}
+
+// envOutput returns the default output format from BACKUP_OUTPUT_FORMAT, or table if it is unset.
+func envOutput() string {
+	if v := os.Getenv(config.EnvOutputFormat); v != "" {
+		return v
+	}
+	return outputTable
+}
+
+// parseSince parses a --since period, either a time.Duration such as 12h or a number of days such as 7d.
+func parseSince(value string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number of days", days)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(value); err != nil {
+			return 0, err
+		}
+	}
+
+	if d <= 0 {
+		return 0, errors.New("must be positive")
+	}
+	return d, nil
+}
+
+// printUsage prints the command line help.
+func printUsage(fs *flag.FlagSet) {
+	out := fs.Output()
+	_, _ = fmt.Fprintf(out, "Usage: %s [flags]\n\n", fs.Name())
+	_, _ = fmt.Fprintln(out, "Backs up the configured directories to S3. Configuration is read from")
+	_, _ = fmt.Fprintln(out, "environment variables, which override values from a YAML file. The YAML file is")
+	_, _ = fmt.Fprintf(out, "the first of: --config-file, %s, $XDG_CONFIG_HOME/s3-backup/config.yaml,\n", config.EnvConfigFile)
+	_, _ = fmt.Fprintln(out, "and s3-backup/config.yaml in each of $XDG_CONFIG_DIRS.")
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintf(out, "Runs a single backup and exits, unless %s is set (e.g. %q).\n\n",
+		config.EnvCronSchedule, config.DefaultCronSchedule)
+	_, _ = fmt.Fprintln(out, "Flags:")
+	fs.PrintDefaults()
+}
+
+// runEstimate prints the size and cost estimate of a backup without uploading anything.
+func runEstimate(ctx context.Context, svc *s3.Service, output string) int {
+	result, err := svc.EstimateBackup(ctx)
+	if err != nil {
+		slog.Error("backup estimate failed", "error", err)
+		return 1
+	}
+
+	if err := printEstimate(os.Stdout, result, output); err != nil {
+		slog.Error("failed to print backup estimate", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// runDryRun logs every object a backup would upload without uploading anything. If
+// BACKUP_DRY_RUN_OUTPUT_FILE is set, the objects are also written to that file as JSON, and
+// with JSON output they are printed to stdout as well.
+func runDryRun(ctx context.Context, svc *s3.Service, output string) int {
+	planned, err := svc.PlanBackup(ctx)
+	if err != nil {
+		slog.Error("dry run failed", "error", err)
+		return 1
+	}
+
+	if output == outputJSON {
+		if err := printPlanned(os.Stdout, planned); err != nil {
+			slog.Error("failed to print dry run output", "error", err)
+			return 1
+		}
+	}
+
+	for _, obj := range planned {
+		slog.Info("dry run: would upload", "file", obj.LocalPath, "key", obj.S3Key, "size_bytes", obj.SizeBytes)
+	}
+	slog.Info("dry run completed", "objects", len(planned))
+
+	if path := os.Getenv(config.EnvDryRunOutputFile); path != "" {
+		if err := writeDryRunOutput(path, planned); err != nil {
+			slog.Error("failed to write dry run output", "file", path, "error", err)
+			return 1
+		}
+		slog.Info("dry run output written", "file", path)
+	}
+	return 0
+}
+
+// writeDryRunOutput writes planned to path as a JSON array. The JSON is written to a temporary
+// file in the same directory that is then renamed over path, so readers never see a partial file.
+func writeDryRunOutput(path string, planned []s3.PlannedObject) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(planned); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to encode dry run output: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
+
+// runListSessions prints the backups in the bucket, limited to those started within opts.since if set.
+func runListSessions(ctx context.Context, svc *s3.Service, opts *cliOptions) int {
+	var since time.Time
+	if opts.since > 0 {
+		since = time.Now().Add(-opts.since)
+	}
+
+	sessions, err := svc.ListBackupSessions(ctx, since)
+	if err != nil {
+		slog.Error("failed to list backup sessions", "error", err)
+		return 1
+	}
+
+	if err := printSessions(os.Stdout, sessions, opts.output); err != nil {
+		slog.Error("failed to print backup sessions", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// printSessions writes the sessions to w in the requested output format.
+func printSessions(w io.Writer, sessions []s3.BackupSession, output string) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sessions)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Timestamp\tFiles\tSize")
+	for _, session := range sessions {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n",
+			session.Timestamp.Format(time.RFC3339), session.FileCount, formatBytes(session.TotalBytes))
+	}
+	return tw.Flush()
+}
+
+// runNextRuns prints the next n scheduled backup times without running a backup.
+func runNextRuns(svc *s3.Service, n int) int {
+	runs, err := svc.UpcomingRuns(n)
+	if err != nil {
+		slog.Error("failed to compute upcoming runs", "error", err)
+		return 1
+	}
+
+	printRuns(os.Stdout, runs)
+	return 0
+}
+
+// printPlanned writes the objects planned by a dry run to w as a JSON array.
+func printPlanned(w io.Writer, planned []s3.PlannedObject) error {
+	if planned == nil {
+		planned = []s3.PlannedObject{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(planned)
+}
+
+// backupResult is the JSON document printed for a one-time backup with JSON output.
+type backupResult struct {
+	Status     string `json:"status"`
+	Files      int    `json:"files"`
+	Bytes      int64  `json:"bytes"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// printBackupResult writes the result of a backup summarized by stats, which returned err, to w
+// as a single JSON document.
+func printBackupResult(w io.Writer, stats s3.BackupStats, err error) error {
+	return json.NewEncoder(w).Encode(backupResult{
+		Status:     stats.Status(err),
+		Files:      stats.FilesUploaded,
+		Bytes:      stats.BytesUploaded,
+		DurationMS: stats.CompletedAt.Sub(stats.StartedAt).Milliseconds(),
+	})
+}
+
+// printRuns writes one RFC 3339 time per line to w.
+func printRuns(w io.Writer, runs []time.Time) {
+	for _, run := range runs {
+		_, _ = fmt.Fprintln(w, run.Format(time.RFC3339))
+	}
+}
+
+// printEstimate writes the estimate to w in the requested output format.
+func printEstimate(w io.Writer, result s3.EstimateResult, output string) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Files\t%d\n", result.FileCount)
+	_, _ = fmt.Fprintf(tw, "Total size\t%s (%d bytes)\n", formatBytes(result.TotalBytes), result.TotalBytes)
+	_, _ = fmt.Fprintf(tw, "Estimated PUT cost\t$%.6f\n", result.EstimatedCostUSD)
+	return tw.Flush()
+}
+
+// formatBytes formats a byte count using binary units, e.g. 1.5 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"s3-backup/internal/config"
+	"s3-backup/internal/s3"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConfigFile(t *testing.T) {
+	// Not run in parallel because it sets environment variables
+
+	tc := map[string]struct {
+		envFile  string
+		wantFile string
+	}{
+		"flag loads the config file": {
+			wantFile: "flag",
+		},
+		"flag takes precedence over the environment variable": {
+			envFile:  "env",
+			wantFile: "flag",
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{
+				"flag": writeConfigFile(t, dir, "flag", "flag-bucket"),
+				"env":  writeConfigFile(t, dir, "env", "env-bucket"),
+			}
+
+			t.Setenv(config.EnvConfigFile, "")
+			if tc.envFile != "" {
+				t.Setenv(config.EnvConfigFile, files[tc.envFile])
+			}
+
+			opts, err := parseFlags([]string{"--config-file", files["flag"]})
+			require.NoError(t, err)
+			require.NoError(t, applyConfigFile(opts))
+
+			cfg, err := config.NewConfig()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantFile+"-bucket", cfg.GetS3Bucket())
+			assert.Equal(t, []string{dir}, cfg.GetBackupDirs())
+		})
+	}
+}
+
+func TestMirrorToLogFile(t *testing.T) {
+	// Not run in parallel because it sets environment variables and the default logger
+
+	path := filepath.Join(t.TempDir(), "s3-backup.log")
+	t.Setenv(config.EnvLogFile, path)
+
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	var stdout bytes.Buffer
+	w, closeLogFile, err := mirrorToLogFile(&stdout)
+	require.NoError(t, err)
+	require.NoError(t, setupLogger(w))
+
+	slog.Info("backup completed successfully")
+	closeLogFile()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "backup completed successfully")
+	assert.Equal(t, stdout.String(), string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestMirrorToLogFile_InvalidMaxSize(t *testing.T) {
+	// Not run in parallel because it sets environment variables
+
+	t.Setenv(config.EnvLogFile, filepath.Join(t.TempDir(), "s3-backup.log"))
+	t.Setenv(config.EnvLogMaxSizeMB, "big")
+
+	_, _, err := mirrorToLogFile(&bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), config.EnvLogMaxSizeMB)
+}
+
+func TestPrintStartupBanner(t *testing.T) {
+	// Not run in parallel because it sets the default logger
+
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	tc := map[string]struct {
+		cfg      *config.Config
+		want     []string
+		wantNone []string
+	}{
+		"defaults": {
+			cfg: &config.Config{BackupDirs: []string{"/data"}},
+			want: []string{
+				"version=dev",
+				"backup_dirs=1",
+				"schedule=one-time",
+				"recursive=false",
+				"incremental=false",
+				"archive_mode=none",
+				"concurrency=1",
+				"checksum_algorithm=none",
+				"encryption_type=bucket-default",
+				`kms_key_id=""`,
+				"object_lock_mode=none",
+			},
+		},
+		"features enabled": {
+			cfg: &config.Config{
+				BackupDirs:        []string{"/data", "/home"},
+				CronSchedule:      "0 2 * * *",
+				Recursive:         true,
+				MaxWalkDepth:      3,
+				Incremental:       true,
+				ArchiveMode:       "tar.gz",
+				Concurrency:       4,
+				ChecksumAlgorithm: "sha256",
+				KMSKeyID:          "arn:aws:kms:us-east-1:123456789012:key/backups",
+				ObjectLockMode:    "GOVERNANCE",
+				RetentionDays:     30,
+			},
+			want: []string{
+				"backup_dirs=2",
+				`schedule="0 2 * * *"`,
+				"recursive=true",
+				"max_walk_depth=3",
+				"incremental=true",
+				"archive_mode=tar.gz",
+				"concurrency=4",
+				"concurrency_per_dir=4",
+				"checksum_algorithm=SHA256",
+				"encryption_type=aws:kms",
+				"kms_key_id=arn***",
+				"object_lock_mode=GOVERNANCE",
+				"retention_days=30",
+			},
+			wantNone: []string{"123456789012"},
+		},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			printStartupBanner(tc.cfg)
+
+			assert.Equal(t, 1, bytes.Count(logs.Bytes(), []byte("\n")), "banner should be a single line")
+			assert.Contains(t, logs.String(), `msg="starting s3-backup"`)
+			for _, want := range tc.want {
+				assert.Contains(t, logs.String(), " "+want)
+			}
+			for _, secret := range tc.wantNone {
+				assert.NotContains(t, logs.String(), secret)
+			}
+		})
+	}
+}
+
+func TestWriteDryRunOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dry-run-result.json")
+	// An existing file is replaced as a whole
+	require.NoError(t, os.WriteFile(path, []byte("stale"), 0600))
+
+	planned := []s3.PlannedObject{
+		{LocalPath: "/data/documents/a.txt", S3Key: "2025-12-15T14-30-00/documents/a.txt", SizeBytes: 4},
+		{LocalPath: "/data/documents/invoices/b.txt", S3Key: "2025-12-15T14-30-00/documents/invoices/b.txt", SizeBytes: 6},
+	}
+	require.NoError(t, writeDryRunOutput(path, planned))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal(data, &entries))
+	require.Len(t, entries, len(planned))
+	for i, entry := range entries {
+		assert.Equal(t, map[string]any{
+			"local_path": planned[i].LocalPath,
+			"s3_key":     planned[i].S3Key,
+			"size_bytes": float64(planned[i].SizeBytes),
+		}, entry)
+	}
+
+	leftovers, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers, "the temp file should be renamed")
+}
+
+func TestWriteDryRunOutput_MissingDirectory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "missing", "dry-run-result.json")
+	require.Error(t, writeDryRunOutput(path, nil))
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+
+	tc := map[string]struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		"days":          {value: "7d", want: 7 * 24 * time.Hour},
+		"hours":         {value: "12h", want: 12 * time.Hour},
+		"mixed":         {value: "1h30m", want: 90 * time.Minute},
+		"invalid days":  {value: "xd", wantErr: true},
+		"invalid value": {value: "week", wantErr: true},
+		"zero":          {value: "0d", wantErr: true},
+		"negative":      {value: "-1h", wantErr: true},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseSince(tc.value)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPrintSessions(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+	sessions := []s3.BackupSession{{Timestamp: ts, FileCount: 3, TotalBytes: 1536}}
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		require.NoError(t, printSessions(&out, sessions, outputTable))
+		assert.Equal(t, "Timestamp             Files  Size\n2025-12-15T14:30:00Z  3      1.5 KiB\n", out.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		require.NoError(t, printSessions(&out, sessions, outputJSON))
+		assert.JSONEq(t, `[{"timestamp":"2025-12-15T14:30:00Z","file_count":3,"total_bytes":1536}]`, out.String())
+	})
+}
+
+func TestParseFlags_Output(t *testing.T) {
+	// Not run in parallel because it sets environment variables
+
+	tc := map[string]struct {
+		env     string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		"defaults to table":            {want: outputTable},
+		"environment variable":         {env: "json", want: outputJSON},
+		"human is an alias of table":   {env: "human", want: outputTable},
+		"flag overrides the variable":  {env: "json", args: []string{"--output", "human"}, want: outputTable},
+		"invalid environment variable": {env: "yaml", wantErr: true},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(config.EnvOutputFormat, tc.env)
+
+			opts, err := parseFlags(tc.args)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, opts.output)
+		})
+	}
+}
+
+func TestPrintBackupResult(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+	stats := s3.BackupStats{
+		StartedAt:     started,
+		CompletedAt:   started.Add(3 * time.Second),
+		FilesUploaded: 42,
+		BytesUploaded: 10240,
+	}
+
+	tc := map[string]struct {
+		err  error
+		want string
+	}{
+		"success": {want: `{"status":"success","files":42,"bytes":10240,"duration_ms":3000}`},
+		"partial": {err: errors.New("one file failed"), want: `{"status":"partial","files":42,"bytes":10240,"duration_ms":3000}`},
+	}
+
+	for name, tc := range tc {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			require.NoError(t, printBackupResult(&out, stats, tc.err))
+			assert.JSONEq(t, tc.want, out.String())
+			// A single document per line, so scripts can read it without a JSON stream parser
+			assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+		})
+	}
+}
+
+func TestPrintPlanned(t *testing.T) {
+	t.Parallel()
+
+	t.Run("objects", func(t *testing.T) {
+		t.Parallel()
+
+		planned := []s3.PlannedObject{{LocalPath: "/data/a.txt", S3Key: "2025-12-15T14-30-00/data/a.txt", SizeBytes: 3}}
+		var out bytes.Buffer
+		require.NoError(t, printPlanned(&out, planned))
+
+		var got []s3.PlannedObject
+		require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		assert.Equal(t, planned, got)
+	})
+
+	t.Run("nothing to upload", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		require.NoError(t, printPlanned(&out, nil))
+		assert.JSONEq(t, `[]`, out.String())
+	})
+}
+
+// writeConfigFile writes a minimal YAML config backing up dir to bucket and returns its path.
+func writeConfigFile(t *testing.T, dir, name, bucket string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name+".yaml")
+	content := "backup_dirs:\n  - " + dir + "\naws_region: us-west-2\ns3_bucket: " + bucket + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}